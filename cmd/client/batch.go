@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func handleBatch(ctx context.Context, c *client.Client) {
+	if len(os.Args) < 4 {
+		printBatchUsage()
+		os.Exit(1)
+	}
+
+	switch os.Args[3] {
+	case "add":
+		batchAddCmd(ctx, c)
+	case "update":
+		batchUpdateCmd(ctx, c)
+	case "remove", "rm":
+		batchRemoveCmd(ctx, c)
+	default:
+		fmt.Printf("Unknown batch command: %s\n", os.Args[3])
+		printBatchUsage()
+		os.Exit(1)
+	}
+}
+
+func printBatchUsage() {
+	fmt.Println("Usage: client review-slots batch <command>")
+	fmt.Println("\nCommands:")
+	fmt.Println("  add [--concurrency N] [--no-validate] <file|->    - Create a slot for every interval")
+	fmt.Println("                        <file> is JSON [{\"start\": ..., \"end\": ...}] or CSV start,end")
+	fmt.Println("  update [--concurrency N] [--no-validate] [--force] <file|-> - Move slots to new times")
+	fmt.Println("                        <file> is JSON [{\"id\": ..., \"start\": ..., \"end\": ...}] or CSV id,start,end")
+	fmt.Println("  remove|rm [--concurrency N] [--force] <file|-|id...> - Remove slots, IDs one per line")
+	fmt.Println("\nUpdate and remove refuse slots booked in the meantime unless --force is given.")
+	fmt.Println("With --atomic, items run one at a time and the first failure rolls back the whole batch.")
+	fmt.Println("\nExamples:")
+	fmt.Println("  client review-slots batch add week.csv")
+	fmt.Println("  client review-slots get | awk '/^Available slots:/{f=1; next} /^$/{f=0} f && $1 == \"ID:\" {print $2}' |")
+	fmt.Println("    client review-slots batch rm -   # remove every free slot of the next 7 days")
+}
+
+func batchAddCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("batch add", "client review-slots batch add [--concurrency N] [--no-validate] [--atomic] <file|->")
+	concurrency := fs.Int("concurrency", client.DefaultBatchConcurrency, "how many requests to run at once")
+	noValidate := fs.Bool("no-validate", false, "skip the client-side slot checks")
+	atomic := fs.Bool("atomic", false, atomicUsage)
+	args := parseArgs(fs, os.Args[4:])
+
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	intervals, err := readIntervals(args[0])
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *noValidate {
+		c.SetSlotValidation(false)
+	}
+
+	if *atomic {
+		runAtomic(ctx, c, "add", len(intervals), func(tx *client.Tx, i int) client.BatchResult {
+			slots, err := tx.AddReviewSlot(ctx, intervals[i].Start, intervals[i].End)
+			return client.BatchResult{Interval: intervals[i], Slots: slots, Err: err}
+		})
+		return
+	}
+	printBatchResults("add", c.AddReviewSlots(ctx, intervals, *concurrency))
+}
+
+func batchUpdateCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("batch update", "client review-slots batch update [--concurrency N] [--no-validate] [--force] [--atomic] <file|->")
+	concurrency := fs.Int("concurrency", client.DefaultBatchConcurrency, "how many requests to run at once")
+	noValidate := fs.Bool("no-validate", false, "skip the client-side slot checks")
+	force := fs.Bool("force", false, "update slots even if they were booked in the meantime")
+	atomic := fs.Bool("atomic", false, atomicUsage)
+	args := parseArgs(fs, os.Args[4:])
+
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	updates, err := readSlotUpdates(args[0])
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *noValidate {
+		c.SetSlotValidation(false)
+	}
+
+	if *atomic && *force {
+		log.Fatal("Error: --force cannot be combined with --atomic, atomic batches never touch booked slots")
+	}
+	if *atomic {
+		runAtomic(ctx, c, "update", len(updates), func(tx *client.Tx, i int) client.BatchResult {
+			u := updates[i]
+			r := client.BatchResult{SlotID: u.SlotID, Interval: u.Interval}
+			slot, err := tx.UpdateReviewSlot(ctx, u.SlotID, u.Start, u.End)
+			if slot != nil {
+				r.Slots = []client.ReviewSlot{*slot}
+			}
+			r.Err = err
+			return r
+		})
+		return
+	}
+	printBatchResults("update", c.UpdateReviewSlots(ctx, updates, *concurrency, *force))
+}
+
+func batchRemoveCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("batch remove", "client review-slots batch remove [--concurrency N] [--force] [--atomic] <file|-|id...>")
+	concurrency := fs.Int("concurrency", client.DefaultBatchConcurrency, "how many requests to run at once")
+	force := fs.Bool("force", false, "remove slots even if they were booked in the meantime")
+	atomic := fs.Bool("atomic", false, atomicUsage)
+	args := parseArgs(fs, os.Args[4:])
+
+	if len(args) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	ids := args
+	if len(args) == 1 {
+		if _, err := os.Stat(args[0]); args[0] == "-" || err == nil {
+			if ids, err = readSlotIDs(args[0]); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+	}
+
+	if *atomic && *force {
+		log.Fatal("Error: --force cannot be combined with --atomic, atomic batches never touch booked slots")
+	}
+	if *atomic {
+		runAtomic(ctx, c, "remove", len(ids), func(tx *client.Tx, i int) client.BatchResult {
+			return client.BatchResult{SlotID: ids[i], Err: tx.RemoveReviewSlot(ctx, ids[i])}
+		})
+		return
+	}
+	printBatchResults("remove", c.RemoveReviewSlots(ctx, ids, *concurrency, *force))
+}
+
+// atomicUsage describes the --atomic flag of the batch commands
+const atomicUsage = "run one item at a time and roll back the whole batch on the first failure"
+
+// printBatchResults prints one row per item and a summary, and exits with
+// status 1 if any item failed
+func printBatchResults(op string, results []client.BatchResult) {
+	printBatchTable(results)
+
+	failed := len(client.FailedResults(results))
+	fmt.Printf("\nBatch %s: %d succeeded, %d failed.\n", op, len(results)-failed, failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func printBatchTable(results []client.BatchResult) {
+	fmt.Printf("%-4s %-8s %-24s %-30s %s\n", "#", "STATUS", "SLOT", "TIME", "DETAILS")
+	for _, r := range results {
+		status, details := "ok", ""
+		if r.Err != nil {
+			status, details = "FAILED", r.Err.Error()
+		}
+
+		slotID := r.SlotID
+		if len(r.Slots) > 0 {
+			ids := make([]string, len(r.Slots))
+			for i, s := range r.Slots {
+				ids[i] = s.ID
+			}
+			slotID = strings.Join(ids, ",")
+		}
+
+		when := ""
+		if !r.Interval.IsEmpty() {
+			when = formatInterval(r.Interval)
+		}
+		fmt.Printf("%-4d %-8s %-24s %-30s %s\n", r.Index+1, status, slotID, when, details)
+	}
+}
+
+// runAtomic runs the n items of a batch one at a time in a transaction. The
+// first failure stops the batch and rolls back the items before it; the
+// rollback report is printed and the command exits with status 1.
+func runAtomic(ctx context.Context, c *client.Client, op string, n int, do func(tx *client.Tx, i int) client.BatchResult) {
+	var results []client.BatchResult
+	report, err := c.Transaction(ctx, func(tx *client.Tx) error {
+		for i := 0; i < n; i++ {
+			r := do(tx, i)
+			r.Index = i
+			results = append(results, r)
+			if r.Err != nil {
+				return r.Err
+			}
+		}
+		return nil
+	})
+
+	printBatchTable(results)
+	if err == nil {
+		fmt.Printf("\nBatch %s: all %d succeeded.\n", op, n)
+		return
+	}
+
+	fmt.Printf("\nBatch %s failed at item %d of %d, %d item(s) not attempted.\n", op, len(results), n, n-len(results))
+	printRollback(report)
+	os.Exit(1)
+}
+
+// printRollback prints what a transaction rollback did
+func printRollback(r *client.TxReport) {
+	if len(r.Compensations) == 0 {
+		fmt.Println("Nothing was changed, nothing to roll back.")
+		return
+	}
+	fmt.Printf("Rolled back %d of %d change(s):\n", len(r.Compensations)-len(r.Failed()), len(r.Compensations))
+	for _, comp := range r.Compensations {
+		status, details := "ok", ""
+		if !comp.RolledBack() {
+			status, details = "FAILED", ": "+comp.Err.Error()
+		}
+		fmt.Printf("  %-8s %s%s\n", status, describeCompensation(comp), details)
+	}
+	if !r.Complete() {
+		fmt.Println("The changes that were not rolled back are still in the calendar.")
+	}
+}
+
+func describeCompensation(comp client.Compensation) string {
+	step := comp.Step
+	switch step.Op {
+	case client.MutationAdd:
+		ids := make([]string, len(step.After))
+		for i, s := range step.After {
+			ids[i] = s.ID
+		}
+		return "remove added slot(s) " + strings.Join(ids, ",")
+	case client.MutationUpdate:
+		return fmt.Sprintf("move slot %s back to %s", step.SlotID, formatInterval(step.Before.Interval()))
+	case client.MutationRemove:
+		return fmt.Sprintf("recreate removed slot %s at %s", step.SlotID, formatInterval(step.Before.Interval()))
+	}
+	return fmt.Sprintf("%s of slot %s", step.Op, step.SlotID)
+}
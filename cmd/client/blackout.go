@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/blackout"
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// blackoutConfigFile is the file quiet hours and blackout ranges are read from
+const blackoutConfigFile = "blackouts.json"
+
+func loadBlackoutConfig() *blackout.Config {
+	path, err := configPath(blackoutConfigFile)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	cfg, err := blackout.LoadConfig(path)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	cfg.Warn = warnSkippedEvent
+	return cfg
+}
+
+var (
+	warnedMu sync.Mutex
+	warned   = make(map[string]bool)
+)
+
+// warnSkippedEvent logs an .ics event the blackouts had to skip, once per run
+func warnSkippedEvent(err error) {
+	warnedMu.Lock()
+	defer warnedMu.Unlock()
+	if !warned[err.Error()] {
+		warned[err.Error()] = true
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// blackoutMode returns the mode blackouts are applied in: modeFlag if set,
+// otherwise the configured mode
+func blackoutMode(cfg *blackout.Config, modeFlag string) blackout.Mode {
+	mode := cfg.Mode
+	if modeFlag != "" {
+		mode = blackout.Mode(modeFlag)
+	}
+	if mode == "" {
+		mode = blackout.ModeClip
+	}
+	if mode != blackout.ModeClip && mode != blackout.ModeReject {
+		log.Fatalf("Error: invalid --blackout %q (use clip, reject or off)", modeFlag)
+	}
+	return mode
+}
+
+// useBlackouts makes every slot the client creates or moves respect the
+// configured blackouts. modeFlag overrides the configured mode; "off"
+// ignores blackouts entirely.
+func useBlackouts(c *client.Client, modeFlag string) {
+	if modeFlag == "off" {
+		c.SetSlotFilter(nil)
+		return
+	}
+	cfg := loadBlackoutConfig()
+	c.SetSlotFilter(cfg.Filter(blackoutMode(cfg, modeFlag)))
+}
+
+// allowedParts returns the parts of a requested slot that may be added,
+// reporting the parts that fall into a blackout. modeFlag is as for
+// useBlackouts. A slot rejected by the blackouts returns a *blackout.Error.
+func allowedParts(modeFlag string, start, end time.Time) ([]client.Interval, error) {
+	if modeFlag == "off" {
+		return []client.Interval{{Start: start, End: end}}, nil
+	}
+
+	cfg := loadBlackoutConfig()
+	res, err := cfg.Apply(blackoutMode(cfg, modeFlag), start, end)
+	if res != nil && res.Changed() {
+		fmt.Println("Blacked-out parts:")
+		for _, b := range res.Clipped {
+			fmt.Printf("  - %s (%s)\n", formatInterval(b.Interval), b.Reason)
+		}
+		if err == nil {
+			fmt.Println("Adding the remaining parts:")
+			for _, iv := range res.Allowed {
+				fmt.Printf("  + %s\n", formatInterval(iv))
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	return res.Allowed, nil
+}
+
+func blackoutsCmd() {
+	fs := newFlagSet("blackouts", "client review-slots blackouts [--days N]")
+	days := fs.Int("days", 7, "how many days ahead to show")
+	fs.Parse(os.Args[3:])
+
+	path, err := configPath(blackoutConfigFile)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	cfg := loadBlackoutConfig()
+
+	from := time.Now()
+	blocks, err := cfg.Blocks(from, from.AddDate(0, 0, *days))
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	mode := cfg.Mode
+	if mode == "" {
+		mode = blackout.ModeClip
+	}
+	fmt.Printf("Blackouts from %s (mode: %s), next %d days: %d\n", path, mode, *days, len(blocks))
+	for i, b := range blocks {
+		fmt.Printf("  %d. %s (%s)\n", i+1, formatInterval(b.Interval), b.Reason)
+	}
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func clearReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("clear", "client review-slots clear --from <time> --to <time> [--snapshot <file>] [--dry-run]")
+	var from, to dateTimeFlag
+	fs.Var(&from, "from", "start of the range to clear")
+	fs.Var(&to, "to", "end of the range to clear")
+	snapshot := fs.String("snapshot", "", "save the removed slots to this file, restorable with 'batch add'")
+	dryRun := fs.Bool("dry-run", false, "only show which slots would be removed")
+	fs.Parse(os.Args[3:])
+
+	if !from.set || !to.set {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if !to.t.After(from.t) {
+		log.Fatal("Error: --to must be after --from")
+	}
+
+	plan, err := c.PlanClear(ctx, from.t, to.t)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if *dryRun {
+		for _, s := range plan.Targets {
+			fmt.Printf("  would remove %s (ID: %s)\n", formatInterval(s.Interval()), s.ID)
+		}
+		fmt.Printf("\n%d free slots would be removed.\n", len(plan.Targets))
+		return
+	}
+
+	// Save the snapshot before removing anything, so an interrupted clear
+	// can still be restored
+	if *snapshot != "" && len(plan.Targets) > 0 {
+		if err := saveSnapshot(*snapshot, plan.Targets); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	result := c.ApplyClear(ctx, plan)
+
+	for _, s := range result.Removed {
+		fmt.Printf("  - removed %s (ID: %s)\n", formatInterval(s.Interval()), s.ID)
+	}
+	for _, s := range result.Skipped {
+		fmt.Printf("  ! skipped %s (ID: %s): only partly inside the range\n", formatInterval(s.Interval()), s.ID)
+	}
+	for _, r := range result.Failed {
+		fmt.Printf("  ! FAILED  %s (ID: %s): %v\n", formatInterval(r.Interval), r.SlotID, r.Err)
+	}
+
+	if *snapshot != "" && len(plan.Targets) > 0 {
+		// Slots that could not be removed are still there; keep them out of
+		// the snapshot so restoring it does not duplicate them
+		if len(result.Failed) > 0 {
+			if err := saveSnapshot(*snapshot, result.Removed); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+		fmt.Printf("\nSnapshot saved to %s. Run 'client review-slots batch add %s' to restore it.\n", *snapshot, *snapshot)
+	}
+
+	fmt.Printf("\nCleared %d free slots.\n", len(result.Removed))
+	if len(result.Failed) > 0 {
+		log.Fatalf("Error: %d slots could not be removed", len(result.Failed))
+	}
+}
+
+// saveSnapshot writes slots in the JSON interval format readIntervals accepts
+func saveSnapshot(path string, slots []client.ReviewSlot) error {
+	items := make([]intervalInput, len(slots))
+	for i, s := range slots {
+		items[i] = intervalInput{
+			Start: s.Start.UTC().Format("2006-01-02T15:04:05Z"),
+			End:   s.End.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+	}
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("save snapshot: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// conflictsUsage describes the --conflicts flag and S21_CONFLICTS
+const conflictsUsage = "per-kind conflict actions, e.g. exam=block,activity=warn,other=ignore"
+
+// applyConflicts sets conflict actions from a comma-separated list of
+// kind=action pairs
+func applyConflicts(c *client.Client, spec string) error {
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		kind, value, ok := strings.Cut(pair, "=")
+		if !ok {
+			return fmt.Errorf("invalid conflict setting %q (use kind=block|warn|ignore)", pair)
+		}
+		k, err := client.ParseEventKind(strings.TrimSpace(kind))
+		if err != nil {
+			return err
+		}
+		action, err := client.ParseConflictAction(strings.TrimSpace(value))
+		if err != nil {
+			return err
+		}
+		c.SetConflictAction(k, action)
+	}
+	return nil
+}
+
+// printConflictWarning reports conflicts the policy lets through
+func printConflictWarning(e *client.ConflictError) {
+	fmt.Fprintf(os.Stderr, "Warning: slot %s overlaps:\n", formatInterval(e.Slot))
+	printConflicts(e)
+}
+
+func printConflicts(e *client.ConflictError) {
+	for _, ev := range e.Conflicts {
+		fmt.Fprintf(os.Stderr, "  - [%s] %s (%s)\n", ev.Kind, ev.Title, formatInterval(ev.Interval))
+	}
+}
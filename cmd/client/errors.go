@@ -0,0 +1,52 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/arseniisemenow/s21gql/pkg/blackout"
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// fatalSlotError prints a slot mutation error and exits. Validation errors
+// are listed one violated rule per line, conflicts one event per line, and
+// booked slots point at --force.
+func fatalSlotError(prefix string, err error) {
+	var vErr *client.ValidationError
+	if errors.As(err, &vErr) {
+		fmt.Fprintf(os.Stderr, "%s: slot %s breaks %d rule(s):\n", prefix, formatInterval(vErr.Slot), len(vErr.Violations))
+		for _, v := range vErr.Violations {
+			fmt.Fprintf(os.Stderr, "  - [%s] %s\n", v.Rule, v.Message)
+		}
+		fmt.Fprintln(os.Stderr, "Use --no-validate to send the request anyway.")
+		os.Exit(1)
+	}
+	var cErr *client.ConflictError
+	if errors.As(err, &cErr) {
+		fmt.Fprintf(os.Stderr, "%s: slot %s conflicts with %d event(s):\n", prefix, formatInterval(cErr.Slot), len(cErr.Conflicts))
+		printConflicts(cErr)
+		fmt.Fprintf(os.Stderr, "Use --conflicts %s=warn (or =ignore) to create it anyway.\n", cErr.Conflicts[0].Kind)
+		os.Exit(1)
+	}
+	var bErr *blackout.Error
+	if errors.As(err, &bErr) {
+		fmt.Fprintf(os.Stderr, "%s: slot %s intersects a blackout:\n", prefix, formatInterval(bErr.Result.Requested))
+		for _, b := range bErr.Result.Clipped {
+			fmt.Fprintf(os.Stderr, "  - %s (%s)\n", formatInterval(b.Interval), b.Reason)
+		}
+		if len(bErr.Result.Allowed) == 0 {
+			fmt.Fprintln(os.Stderr, "Nothing is left outside the blackouts. Use --blackout off to ignore them.")
+		} else {
+			fmt.Fprintln(os.Stderr, "Use --blackout clip to add the remaining parts, or --blackout off to ignore blackouts.")
+		}
+		os.Exit(1)
+	}
+	if errors.Is(err, client.ErrSlotBooked) {
+		fmt.Fprintf(os.Stderr, "%s: refusing to touch a booked slot: %v\n", prefix, err)
+		fmt.Fprintln(os.Stderr, "Use --force to do it anyway and cancel the review.")
+		os.Exit(1)
+	}
+	log.Fatalf("%s: %v", prefix, err)
+}
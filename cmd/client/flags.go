@@ -0,0 +1,71 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"time"
+)
+
+// dateTimeFlag is a flag.Value that accepts every format supported by
+// parseDateTime
+type dateTimeFlag struct {
+	t   time.Time
+	set bool
+}
+
+func (f *dateTimeFlag) String() string {
+	if !f.set {
+		return ""
+	}
+	return f.t.Format("2006-01-02 15:04")
+}
+
+func (f *dateTimeFlag) Set(s string) error {
+	t, err := parseDateTime(s)
+	if err != nil {
+		return err
+	}
+	f.t = t
+	f.set = true
+	return nil
+}
+
+// newFlagSet creates a flag set for a review-slots subcommand. Usage errors
+// print the given usage line followed by the flag defaults.
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s\n", usage)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// stringListFlag is a flag.Value that collects every occurrence of a
+// repeatable flag
+type stringListFlag []string
+
+func (f *stringListFlag) String() string {
+	return fmt.Sprint([]string(*f))
+}
+
+func (f *stringListFlag) Set(s string) error {
+	*f = append(*f, s)
+	return nil
+}
+
+// parseArgs parses flags that may appear before, between or after
+// positional arguments and returns the positional arguments
+func parseArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		fs.Parse(args)
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
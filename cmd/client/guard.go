@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/guard"
+)
+
+func pruneSoonCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("prune-soon", "client review-slots prune-soon --lead <duration> [--trim] [--dry-run]")
+	lead := fs.Duration("lead", 45*time.Minute, "withdraw free slots starting within this time from now")
+	trim := fs.Bool("trim", false, "keep the part of long slots after the lead time instead of removing them")
+	dryRun := fs.Bool("dry-run", false, "only show what would be withdrawn")
+	fs.Parse(os.Args[3:])
+
+	policy := guard.NewPruneSoon(*lead, *trim)
+
+	if *dryRun {
+		now := time.Now()
+		slots, _, err := c.GetReviewSlots(ctx, now.Add(-client.DefaultSlotRules.MaxDuration), now.Add(*lead))
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		actions := policy.Plan(now, slots)
+		if len(actions) == 0 {
+			log.Printf("No free slots start within %s", *lead)
+		}
+		for _, a := range actions {
+			log.Printf("would %s", a)
+		}
+		return
+	}
+
+	if err := guard.RunOnce(ctx, c, []guard.Policy{policy}, logAction); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+func guardCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("guard", "client guard [--lead <duration>] [--trim] [--per-day N] [--per-week N] [--keep-target <d> [--keep-days N] [--keep-max <d>] [--keep-hours HH:MM-HH:MM]...] [--interval <duration>]")
+	lead := fs.Duration("lead", 45*time.Minute, "withdraw free slots starting within this time from now")
+	trim := fs.Bool("trim", false, "keep the part of long slots after the lead time instead of removing them")
+	perDay, perWeek := quotaFlags(fs)
+	keep := keeperFlags(fs, "keep-", 0)
+	interval := fs.Duration("interval", time.Minute, "how often to check the calendar")
+	fs.Parse(os.Args[2:])
+
+	policies := []guard.Policy{guard.NewPruneSoon(*lead, *trim)}
+	var quota *guard.Quota
+	if *perDay > 0 || *perWeek > 0 {
+		q, err := newQuota(ctx, c, *perDay, *perWeek)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		policies = append(policies, q)
+		quota = &q
+	}
+	if *keep.target > 0 {
+		k, err := keep.keeper()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		// Stay clear of what the other policies withdraw, so that the
+		// keeper does not add slots only to see them removed next round
+		k.Lead = max(k.Lead, *lead)
+		k.Quota = quota
+		policies = append(policies, k)
+	}
+
+	log.Printf("Guard started: checking every %s, press Ctrl+C to stop", *interval)
+	guard.Run(ctx, c, policies, *interval, requestTimeout, logAction, func(err error) {
+		log.Printf("Error: %v", err)
+	})
+	log.Println("Guard stopped")
+}
+
+// logAction logs a change made by a guard policy
+func logAction(a guard.Action) {
+	log.Print(a)
+}
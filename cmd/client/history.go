@@ -0,0 +1,233 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/journal"
+)
+
+// journalFile is the data file observed changes and mutations are recorded in
+const journalFile = "journal.jsonl"
+
+// defaultHistoryKeepDays is how long the journal keeps entries unless
+// S21_HISTORY_KEEP_DAYS says otherwise
+const defaultHistoryKeepDays = 180
+
+// openJournal returns the journal selected by S21_JOURNAL: a file path, off
+// to disable recording, or journal.jsonl in the data dir if unset. It
+// returns nil when the journal is disabled.
+func openJournal() (*journal.Journal, error) {
+	path := os.Getenv("S21_JOURNAL")
+	if path == "off" {
+		return nil, nil
+	}
+	if path == "" {
+		var err error
+		if path, err = dataPath(journalFile); err != nil {
+			return nil, err
+		}
+	}
+	return journal.New(path), nil
+}
+
+// journalRetention reads the retention settings from S21_HISTORY_KEEP_DAYS
+// and S21_HISTORY_KEEP_ENTRIES, where 0 keeps everything
+func journalRetention() (journal.Retention, error) {
+	r := journal.Retention{MaxAge: defaultHistoryKeepDays * 24 * time.Hour}
+	if s := os.Getenv("S21_HISTORY_KEEP_DAYS"); s != "" {
+		days, err := strconv.Atoi(s)
+		if err != nil || days < 0 {
+			return r, fmt.Errorf("invalid S21_HISTORY_KEEP_DAYS %q", s)
+		}
+		r.MaxAge = time.Duration(days) * 24 * time.Hour
+	}
+	if s := os.Getenv("S21_HISTORY_KEEP_ENTRIES"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n < 0 {
+			return r, fmt.Errorf("invalid S21_HISTORY_KEEP_ENTRIES %q", s)
+		}
+		r.MaxEntries = n
+	}
+	return r, nil
+}
+
+// recordMutations returns a mutation observer that appends to the journal.
+// A failed write only warns: the mutation itself already went through.
+func recordMutations(j *journal.Journal) func(client.Mutation) {
+	return func(m client.Mutation) {
+		if err := j.Append(journal.MutationEntry(m)); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+}
+
+func historyCmd() {
+	if len(os.Args) > 2 && os.Args[2] == "compact" {
+		compactHistoryCmd()
+		return
+	}
+
+	fs := newFlagSet("history", "client history [--days N] [--from <time>] [--to <time>] [--project <name>] [--verifier <login>] [--kind change|mutation] [--json]")
+	days := fs.Int("days", 30, "how many days back to show, unless --from is given")
+	var from, to dateTimeFlag
+	fs.Var(&from, "from", "show entries recorded at or after this time")
+	fs.Var(&to, "to", "show entries recorded before this time")
+	project := fs.String("project", "", "only bookings of projects whose name contains this")
+	verifier := fs.String("verifier", "", "only bookings with this verifier login")
+	kind := fs.String("kind", "", "only observed changes (change) or our own mutations (mutation)")
+	asJSON := fs.Bool("json", false, "print the entries as JSON Lines")
+	fs.Parse(os.Args[2:])
+
+	if *kind != "" && *kind != string(journal.KindChange) && *kind != string(journal.KindMutation) {
+		log.Fatalf("Error: invalid --kind %q (use change or mutation)", *kind)
+	}
+
+	j, err := openJournal()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if j == nil {
+		log.Fatal("Error: the journal is disabled by S21_JOURNAL=off")
+	}
+
+	f := journal.Filter{
+		From:     time.Now().AddDate(0, 0, -*days),
+		Kind:     journal.Kind(*kind),
+		Project:  *project,
+		Verifier: *verifier,
+	}
+	if from.set {
+		f.From = from.t
+	}
+	if to.set {
+		f.To = to.t
+	}
+
+	entries, err := j.Query(f)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		for _, e := range entries {
+			if err := enc.Encode(e); err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+		}
+		return
+	}
+
+	if len(entries) == 0 {
+		fmt.Println("No history recorded for this filter.")
+		return
+	}
+	for _, e := range entries {
+		fmt.Printf("%s  %-16s %s\n", e.Time.Local().Format("2006-01-02 15:04:05"), e.Type, describeEntry(e))
+	}
+	fmt.Printf("\n%d entries from %s\n", len(entries), j.Path())
+}
+
+func compactHistoryCmd() {
+	fs := newFlagSet("compact", "client history compact [--keep-days N] [--keep N]")
+	retention, err := journalRetention()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	keepDays := fs.Int("keep-days", int(retention.MaxAge/(24*time.Hour)), "drop entries older than this many days, 0 keeps all (default from S21_HISTORY_KEEP_DAYS)")
+	keep := fs.Int("keep", retention.MaxEntries, "keep at most this many newest entries, 0 keeps all (default from S21_HISTORY_KEEP_ENTRIES)")
+	fs.Parse(os.Args[3:])
+
+	j, err := openJournal()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if j == nil {
+		log.Fatal("Error: the journal is disabled by S21_JOURNAL=off")
+	}
+
+	res, err := j.Compact(journal.Retention{
+		MaxAge:     time.Duration(*keepDays) * 24 * time.Hour,
+		MaxEntries: *keep,
+	}, time.Now())
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	fmt.Printf("Kept %d entries, dropped %d expired and %d duplicates.\n", res.Kept, res.Expired, res.Duplicates)
+}
+
+// compactJournal applies the configured retention, for long-running
+// commands that append to the journal
+func compactJournal(j *journal.Journal) {
+	retention, err := journalRetention()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if _, err := j.Compact(retention, time.Now()); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+}
+
+// describeEntry summarizes what a journal entry records on one line
+func describeEntry(e journal.Entry) string {
+	if m := e.Mutation; m != nil {
+		var parts []string
+		if m.SlotID != "" {
+			parts = append(parts, "slot "+m.SlotID)
+		}
+		if m.Before != nil {
+			parts = append(parts, "was "+formatInterval(m.Before.Interval()))
+		}
+		if m.Requested != nil {
+			parts = append(parts, "to "+formatInterval(*m.Requested))
+		}
+		for _, s := range m.After {
+			if s.ID != m.SlotID {
+				parts = append(parts, "created "+s.ID)
+			}
+		}
+		if e.Undoes != "" {
+			parts = append(parts, "undo")
+		}
+		if m.Failed() {
+			parts = append(parts, "FAILED: "+m.Error)
+		}
+		return strings.Join(parts, ", ")
+	}
+
+	ch := e.Change
+	if ch == nil {
+		return ""
+	}
+	if b := e.Booking(); b != nil {
+		s := fmt.Sprintf("%s by %s, %s", b.ProjectName, b.VerifierLogin, formatInterval(b.Interval()))
+		if ch.BookingBefore != nil && ch.BookingAfter != nil && ch.BookingBefore.Status != ch.BookingAfter.Status {
+			s += fmt.Sprintf(" (%s -> %s)", ch.BookingBefore.Status, ch.BookingAfter.Status)
+		}
+		return s
+	}
+	if ch.SlotBefore != nil && ch.SlotAfter != nil && !ch.SlotBefore.Interval().Equal(ch.SlotAfter.Interval()) {
+		return fmt.Sprintf("slot %s, %s -> %s", ch.SlotAfter.ID,
+			formatInterval(ch.SlotBefore.Interval()), formatInterval(ch.SlotAfter.Interval()))
+	}
+	if s := ch.SlotAfter; s != nil {
+		return fmt.Sprintf("slot %s, %s", s.ID, formatInterval(s.Interval()))
+	}
+	if s := ch.SlotBefore; s != nil {
+		return fmt.Sprintf("slot %s, %s", s.ID, formatInterval(s.Interval()))
+	}
+	if ev := ch.EventAfter; ev != nil {
+		return ev.String()
+	}
+	if ev := ch.EventBefore; ev != nil {
+		return ev.String()
+	}
+	return ""
+}
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// intervalInput is the JSON form of an interval in input files
+type intervalInput struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// readIntervals reads intervals from a JSON or CSV file, or from stdin when
+// path is "-". JSON input is an array of {"start": ..., "end": ...} objects,
+// CSV input has one "start,end" pair per line with an optional header.
+// Times accept every format supported by parseDateTime.
+func readIntervals(path string) ([]client.Interval, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("read intervals: %w", err)
+	}
+
+	if len(data) > 0 && data[0] == '[' {
+		return parseIntervalsJSON(data)
+	}
+	return parseIntervalsCSV(data)
+}
+
+// readInput reads a whole file, or stdin when path is "-", with surrounding
+// whitespace removed
+func readInput(path string) ([]byte, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	return bytes.TrimSpace(data), err
+}
+
+func parseIntervalsJSON(data []byte) ([]client.Interval, error) {
+	var items []intervalInput
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("decode intervals: %w", err)
+	}
+
+	intervals := make([]client.Interval, 0, len(items))
+	for i, item := range items {
+		iv, err := parseInterval(item.Start, item.End)
+		if err != nil {
+			return nil, fmt.Errorf("interval %d: %w", i+1, err)
+		}
+		intervals = append(intervals, iv)
+	}
+	return intervals, nil
+}
+
+func parseIntervalsCSV(data []byte) ([]client.Interval, error) {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = 2
+	r.TrimLeadingSpace = true
+	r.Comment = '#'
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("decode intervals: %w", err)
+	}
+
+	var intervals []client.Interval
+	for i, rec := range records {
+		if i == 0 && strings.EqualFold(rec[0], "start") {
+			continue
+		}
+		iv, err := parseInterval(rec[0], rec[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", i+1, err)
+		}
+		intervals = append(intervals, iv)
+	}
+	return intervals, nil
+}
+
+// parseInterval parses a start/end pair and checks that it is not empty
+func parseInterval(startStr, endStr string) (client.Interval, error) {
+	start, err := parseDateTime(strings.TrimSpace(startStr))
+	if err != nil {
+		return client.Interval{}, err
+	}
+	end, err := parseDateTime(strings.TrimSpace(endStr))
+	if err != nil {
+		return client.Interval{}, err
+	}
+
+	iv := client.Interval{Start: start, End: end}
+	if iv.IsEmpty() {
+		return client.Interval{}, fmt.Errorf("end time must be after start time: %s - %s", startStr, endStr)
+	}
+	return iv, nil
+}
+
+// formatInterval formats an interval for CLI output, omitting the end date
+// when the interval does not cross midnight
+func formatInterval(iv client.Interval) string {
+	if iv.Start.Format("2006-01-02") == iv.End.Format("2006-01-02") {
+		return fmt.Sprintf("%s - %s", iv.Start.Format("2006-01-02 15:04"), iv.End.Format("15:04"))
+	}
+	return fmt.Sprintf("%s - %s", iv.Start.Format("2006-01-02 15:04"), iv.End.Format("2006-01-02 15:04"))
+}
+
+// slotUpdateInput is the JSON form of a slot update in input files
+type slotUpdateInput struct {
+	ID    string `json:"id"`
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+// readSlotUpdates reads slot updates from a JSON or CSV file, or from stdin
+// when path is "-". JSON input is an array of {"id": ..., "start": ...,
+// "end": ...} objects, CSV input has one "id,start,end" triple per line with
+// an optional header.
+func readSlotUpdates(path string) ([]client.SlotUpdate, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("read slot updates: %w", err)
+	}
+
+	var items []slotUpdateInput
+	if len(data) > 0 && data[0] == '[' {
+		if err := json.Unmarshal(data, &items); err != nil {
+			return nil, fmt.Errorf("decode slot updates: %w", err)
+		}
+	} else {
+		r := csv.NewReader(bytes.NewReader(data))
+		r.FieldsPerRecord = 3
+		r.TrimLeadingSpace = true
+		r.Comment = '#'
+		records, err := r.ReadAll()
+		if err != nil {
+			return nil, fmt.Errorf("decode slot updates: %w", err)
+		}
+		for i, rec := range records {
+			if i == 0 && strings.EqualFold(rec[0], "id") {
+				continue
+			}
+			items = append(items, slotUpdateInput{ID: rec[0], Start: rec[1], End: rec[2]})
+		}
+	}
+
+	updates := make([]client.SlotUpdate, 0, len(items))
+	for i, item := range items {
+		if item.ID == "" {
+			return nil, fmt.Errorf("update %d: missing slot id", i+1)
+		}
+		iv, err := parseInterval(item.Start, item.End)
+		if err != nil {
+			return nil, fmt.Errorf("update %d: %w", i+1, err)
+		}
+		updates = append(updates, client.SlotUpdate{SlotID: item.ID, Interval: iv})
+	}
+	return updates, nil
+}
+
+// readSlotIDs reads slot IDs, one per line, from a file or from stdin when
+// path is "-". Blank lines and lines starting with # are skipped.
+func readSlotIDs(path string) ([]string, error) {
+	data, err := readInput(path)
+	if err != nil {
+		return nil, fmt.Errorf("read slot ids: %w", err)
+	}
+
+	var ids []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		ids = append(ids, line)
+	}
+	return ids, nil
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/guard"
+)
+
+// keeperOptions are the flags of the rolling horizon keeper, shared by
+// review-slots keep and guard
+type keeperOptions struct {
+	days    *int
+	target  *time.Duration
+	max     *time.Duration
+	hours   stringListFlag
+	buffer  *time.Duration
+	minSlot *time.Duration
+	tz      *string
+}
+
+// keeperFlags registers the keeper flags, each name prefixed with prefix
+func keeperFlags(fs *flag.FlagSet, prefix string, target time.Duration) *keeperOptions {
+	o := &keeperOptions{}
+	o.days = fs.Int(prefix+"days", 3, "how many days ahead to keep covered, today included")
+	o.target = fs.Duration(prefix+"target", target, "availability to keep per day, booked reviews included")
+	o.max = fs.Duration(prefix+"max", 0, "most availability per day, 0 for no cap")
+	fs.Var(&o.hours, prefix+"hours", "preferred hours for new slots, e.g. 10:00-20:00 (repeatable, default: the whole day)")
+	o.buffer = fs.Duration(prefix+"buffer", 15*time.Minute, "time to keep free around other events")
+	o.minSlot = fs.Duration(prefix+"min", client.DefaultSlotRules.MinDuration, "shortest slot worth adding")
+	o.tz = fs.String(prefix+"tz", "", "IANA time zone of days and hours (default: local zone)")
+	return o
+}
+
+// keeper builds the keeper policy from the flags
+func (o *keeperOptions) keeper() (guard.Keeper, error) {
+	var windows []guard.Window
+	for _, h := range o.hours {
+		start, end, err := parseWorkingHours(h)
+		if err != nil {
+			return guard.Keeper{}, err
+		}
+		windows = append(windows, guard.Window{Start: start, End: end})
+	}
+	if *o.max > 0 && *o.max < *o.target {
+		return guard.Keeper{}, fmt.Errorf("daily maximum %s is below the target %s", *o.max, *o.target)
+	}
+
+	k := guard.NewKeeper(*o.days, *o.target, *o.max, windows)
+	k.Buffer = *o.buffer
+	k.MinSlot = *o.minSlot
+	k.Blackouts = loadBlackoutConfig()
+	if *o.tz != "" {
+		loc, err := time.LoadLocation(*o.tz)
+		if err != nil {
+			return guard.Keeper{}, fmt.Errorf("invalid time zone %q: %v", *o.tz, err)
+		}
+		k.Location = loc
+	}
+	return k, nil
+}
+
+func keepReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("keep", "client review-slots keep [--days N] [--target <d>] [--max <d>] [--hours HH:MM-HH:MM]... [--buffer <d>] [--min <d>] [--tz Zone] [--dry-run]")
+	opts := keeperFlags(fs, "", 2*time.Hour)
+	dryRun := fs.Bool("dry-run", false, "only show the slots that would be added")
+	fs.Parse(os.Args[3:])
+
+	k, err := opts.keeper()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if k.Days <= 0 || k.Target <= 0 {
+		log.Fatal("Error: --days and --target must be positive")
+	}
+
+	if *dryRun {
+		now := time.Now()
+		r := k.Range(now)
+		slots, _, err := c.GetReviewSlots(ctx, r.Start, r.End)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		busy, err := c.GetBusyEvents(ctx, r.Start.Add(-k.Buffer), r.End.Add(k.Buffer))
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		blocked, err := k.Blocked(now)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		printCoverage(k.Coverage(now, slots))
+		actions := k.Plan(now, slots, busy, blocked, nil)
+		if len(actions) == 0 {
+			fmt.Println("\nNothing to add.")
+			return
+		}
+		fmt.Println("\nWould add:")
+		for _, a := range actions {
+			fmt.Printf("  + %s  # %s\n", formatInterval(a.Before), a.Reason)
+		}
+		return
+	}
+
+	if err := guard.RunOnce(ctx, c, []guard.Policy{k}, logAction); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	now := time.Now()
+	r := k.Range(now)
+	slots, _, err := c.GetReviewSlots(ctx, r.Start, r.End)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	printCoverage(k.Coverage(now, slots))
+}
+
+// printCoverage shows the availability of each day against the target
+func printCoverage(coverage []guard.DayCoverage) {
+	fmt.Println("Availability per day:")
+	for _, d := range coverage {
+		status := ""
+		if d.Short() {
+			status = "  SHORT"
+		}
+		fmt.Printf("  %-16s %6s of %s%s\n", d.Day.Format("Mon 2006-01-02"), formatMinutes(int(d.Available.Minutes())), formatMinutes(int(d.Target.Minutes())), status)
+	}
+}
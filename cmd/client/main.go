@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/arseniisemenow/s21gql/pkg/client"
@@ -20,12 +22,27 @@ func formatDateTimeMilli(t time.Time) string {
 	return t.Format("2006-01-02T15:04:05.000Z")
 }
 
+// requestTimeout bounds one-shot commands
+const requestTimeout = 30 * time.Second
+
+// longRunningCommands run until interrupted instead of within requestTimeout
+var longRunningCommands = map[string]bool{
+	"guard": true,
+	"watch": true,
+}
+
 func main() {
 	if len(os.Args) < 2 {
 		printUsage()
 		os.Exit(1)
 	}
 
+	// The history command only reads the local journal
+	if os.Args[1] == "history" {
+		historyCmd()
+		return
+	}
+
 	login := os.Getenv("S21_LOGIN")
 	password := os.Getenv("S21_PASSWORD")
 
@@ -53,12 +70,39 @@ func main() {
 		opts = append(opts, client.WithEduOrgUnitID(orgUnitID))
 	}
 
-	c := client.NewClient(authConfig, opts...)
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	opts = append(opts, client.WithSlotRules(client.DefaultSlotRules), client.WithConflictHandler(printConflictWarning))
+
+	j, err := openJournal()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if j != nil {
+		opts = append(opts, client.WithMutationObserver(recordMutations(j)))
+	}
 
+	c := client.NewClient(authConfig, opts...)
+	if spec := os.Getenv("S21_CONFLICTS"); spec != "" {
+		if err := applyConflicts(c, spec); err != nil {
+			log.Fatalf("Error in S21_CONFLICTS: %v", err)
+		}
+	}
 	cmd := os.Args[1]
 
+	// Every command that creates or moves slots stays out of the blackouts
+	if cmd == "review-slots" || cmd == "guard" {
+		useBlackouts(c, "")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Long-running commands manage their own per-request timeouts
+	if !longRunningCommands[cmd] {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, requestTimeout)
+		defer cancel()
+	}
+
 	switch cmd {
 	case "user":
 		getCurrentUser(ctx, c)
@@ -72,6 +116,12 @@ func main() {
 		getCalendar(ctx, c)
 	case "review-slots":
 		handleReviewSlots(ctx, c)
+	case "guard":
+		guardCmd(ctx, c)
+	case "watch":
+		watchCmd(ctx, c)
+	case "stats":
+		statsCmd(ctx, c)
 	default:
 		fmt.Printf("Unknown command: %s\n", cmd)
 		printUsage()
@@ -105,6 +155,24 @@ func parseDateTime(s string) (time.Time, error) {
 	return time.Time{}, fmt.Errorf("unable to parse datetime: %s (try formats like: 2025-01-15, 2025-01-15 14:30, 2025-01-15T14:30:00Z)", s)
 }
 
+// formatOnline describes where a review takes place
+func formatOnline(online bool, vcLink *string) string {
+	if !online {
+		return "Offline"
+	}
+	if vcLink != nil && *vcLink != "" {
+		return "Online: " + *vcLink
+	}
+	return "Online"
+}
+
+// lookupReviewSlot finds a slot by ID from a day ago up to the furthest
+// point a slot can be created at
+func lookupReviewSlot(ctx context.Context, c *client.Client, slotID string) (*client.ReviewSlot, error) {
+	now := time.Now()
+	return c.FindReviewSlot(ctx, slotID, now.Add(-24*time.Hour), now.Add(client.DefaultSlotRules.MaxHorizon))
+}
+
 func handleReviewSlots(ctx context.Context, c *client.Client) {
 	if len(os.Args) < 3 {
 		printReviewSlotsUsage()
@@ -122,6 +190,36 @@ func handleReviewSlots(ctx context.Context, c *client.Client) {
 		updateReviewSlotCmd(ctx, c)
 	case "remove", "rm":
 		removeReviewSlotCmd(ctx, c)
+	case "plan":
+		planReviewSlotsCmd(ctx, c)
+	case "apply":
+		applyReviewSlotsCmd(ctx, c)
+	case "recur":
+		handleRecur(ctx, c)
+	case "normalize":
+		normalizeReviewSlotsCmd(ctx, c)
+	case "split":
+		splitReviewSlotCmd(ctx, c)
+	case "prune-soon":
+		pruneSoonCmd(ctx, c)
+	case "blackouts":
+		blackoutsCmd()
+	case "quota":
+		quotaCmd(ctx, c)
+	case "keep":
+		keepReviewSlotsCmd(ctx, c)
+	case "batch":
+		handleBatch(ctx, c)
+	case "clear":
+		clearReviewSlotsCmd(ctx, c)
+	case "shift":
+		shiftReviewSlotsCmd(ctx, c)
+	case "suggest":
+		suggestReviewSlotsCmd(ctx, c)
+	case "show":
+		showBookingCmd(ctx, c)
+	case "undo":
+		undoReviewSlotsCmd(ctx, c)
 	default:
 		fmt.Printf("Unknown review-slots command: %s\n", subCmd)
 		printReviewSlotsUsage()
@@ -137,7 +235,11 @@ func printUsage() {
 	fmt.Println("  reviews       - Get upcoming reviews")
 	fmt.Println("  projects      - Get available projects")
 	fmt.Println("  calendar      - Get calendar events")
-	fmt.Println("  review-slots  - Manage review slots (get/add/update/remove)")
+	fmt.Println("  review-slots  - Manage review slots (run 'client review-slots' for subcommands)")
+	fmt.Println("  guard         - Keep enforcing slot policies until interrupted")
+	fmt.Println("  watch         - Report booking changes to hooks and record calendar history until interrupted")
+	fmt.Println("  history       - Show the recorded calendar changes and slot mutations")
+	fmt.Println("  stats         - Report review load per project, week and hour of day")
 	fmt.Println("\nEnvironment variables:")
 	fmt.Println("  S21_LOGIN           - Your 21-school login")
 	fmt.Println("  S21_PASSWORD        - Your 21-school password")
@@ -145,6 +247,12 @@ func printUsage() {
 	fmt.Println("  S21_USER_ROLE       - User role (e.g., STUDENT)")
 	fmt.Println("  S21_EDU_PRODUCT_ID  - Edu Product ID (from browser)")
 	fmt.Println("  S21_EDU_ORG_UNIT_ID - Edu Org Unit ID (from browser)")
+	fmt.Println("  S21_CONFIG_DIR      - Directory for CLI config files (default: <user config dir>/s21gql)")
+	fmt.Println("  S21_CONFLICTS       - Conflict actions per event kind, e.g. exam=block,other=ignore")
+	fmt.Println("  S21_DATA_DIR        - Directory for CLI state (default: $XDG_DATA_HOME/s21gql or ~/.local/share/s21gql)")
+	fmt.Println("  S21_JOURNAL         - Journal file (default: journal.jsonl in the data dir), or off")
+	fmt.Println("  S21_HISTORY_KEEP_DAYS    - Days of history the journal keeps (default: 180, 0 keeps all)")
+	fmt.Println("  S21_HISTORY_KEEP_ENTRIES - Most entries the journal keeps (default: 0, no limit)")
 }
 
 func getCurrentUser(ctx context.Context, c *client.Client) {
@@ -310,13 +418,32 @@ func printReviewSlotsUsage() {
 	fmt.Println("Usage: client review-slots <command>")
 	fmt.Println("\nCommands:")
 	fmt.Println("  get [days]           - Show available and booked review slots (default: 7 days)")
-	fmt.Println("  add <start> <end>    - Add a new review slot")
+	fmt.Println("  add [--no-validate] [--allow-duplicate] [--blackout clip|reject|off] <start> <end>")
+	fmt.Println("                       - Add a review slot where existing slots do not cover the interval yet")
 	fmt.Println("                        Format: YYYY-MM-DD HH:MM or YYYY-MM-DDTHH:MM:SSZ")
 	fmt.Println("                        Example: client review-slots add '2025-01-15 14:00' '2025-01-15 14:30'")
-	fmt.Println("  update <id> <start> <end> - Update an existing review slot")
+	fmt.Println("  update [--no-validate] [--force] <id> <start> <end> - Update an existing review slot")
 	fmt.Println("                        Example: client review-slots update slot-123 '2025-01-15 15:00' '2025-01-15 15:30'")
-	fmt.Println("  remove|rm <id>       - Remove a review slot")
+	fmt.Println("  remove|rm [--force] <id> - Remove a review slot (refuses if it has been booked)")
 	fmt.Println("                        Example: client review-slots remove slot-123")
+	fmt.Println("  plan [--from T] [--to T] [--out F] <desired> - Show what it takes to match the desired intervals")
+	fmt.Println("                        <desired> is a JSON or CSV file of start/end pairs, or - for stdin")
+	fmt.Println("  apply [--force] <plan.json> - Execute a saved plan if the calendar has not changed since")
+	fmt.Println("  recur add|list|remove|expand - Manage recurring availability rules")
+	fmt.Println("  normalize [--days N] [--dry-run] - Merge adjacent or overlapping free slots")
+	fmt.Println("  split <id> --every <d> - Break a free slot into pieces of the given length")
+	fmt.Println("  prune-soon --lead <d> [--trim] [--dry-run] - Withdraw free slots starting within the lead time")
+	fmt.Println("  blackouts [--days N] - Show quiet hours and blackout ranges from blackouts.json")
+	fmt.Println("  quota [--per-day N] [--per-week N] [--enforce] - Show reviews conducted against the caps")
+	fmt.Println("  keep [--days N] [--target <d>] [--max <d>] [--hours HH:MM-HH:MM]... [--dry-run]")
+	fmt.Println("                       - Top up each of the next days to the target availability")
+	fmt.Println("  batch add|update|remove <file|-> - Create, move or remove many slots at once")
+	fmt.Println("  clear --from T --to T [--snapshot F] [--dry-run] - Remove every free slot in a range")
+	fmt.Println("  shift --from T --to T --by <d> - Move every free slot starting in a range by an offset")
+	fmt.Println("  show <booking-id> [--days N] - Show a booking with the task, reviewees and team")
+	fmt.Println("  undo [--dry-run] [n] - Reverse the last n slot changes (default: 1), unless booked since")
+	fmt.Println("  suggest [--days N] [--min <d>] [--buffer <d>] [--hours HH:MM-HH:MM] [--create]")
+	fmt.Println("                       - Find gaps around exams, activities, penalties and reviews")
 	fmt.Println("\nExamples:")
 	fmt.Println("  client review-slots get           # Show slots for next 7 days")
 	fmt.Println("  client review-slots get 30        # Show slots for next 30 days")
@@ -362,33 +489,63 @@ func getReviewSlotsCmd(ctx context.Context, c *client.Client) {
 					s.Start.Format("2006-01-02 15:04"),
 					s.End.Format("15:04"),
 					s.School)
-				fmt.Printf("     ID: %s\n", s.ID)
+				fmt.Printf("     ID: %s | Role: %s\n", s.ID, s.Role)
 				idx++
 			}
 		}
 	}
 
-	// Show booked reviews
-	if len(bookings) > 0 {
-		fmt.Println("\nBooked reviews:")
-		for i, b := range bookings {
-			fmt.Printf("  %d. %s on %s\n", i+1, b.ProjectName,
-				b.Start.Format("2006-01-02 15:04"))
-			fmt.Printf("     Verifier: %s | Status: %s\n", b.VerifierLogin, b.Status)
-			fmt.Printf("     SlotID: %s\n", b.SlotID)
+	// Show booked slots with how the review takes place
+	if bookedCount > 0 {
+		fmt.Println("\nBooked slots:")
+		idx := 1
+		for _, s := range slots {
+			if s.Type != "FREE_TIME" {
+				fmt.Printf("  %d. %s - %s [%s]\n", idx,
+					s.Start.Format("2006-01-02 15:04"),
+					s.End.Format("15:04"),
+					s.School)
+				fmt.Printf("     ID: %s | Role: %s | %s\n", s.ID, s.Role, formatOnline(s.IsOnline, s.VCLink))
+				idx++
+			}
 		}
 	}
+
+	// Show booked reviews, split by which side of the review we are on
+	reviewing, reviewed := client.SplitBookings(bookings)
+	printBookings("I'm reviewing", reviewing)
+	printBookings("I'm being reviewed", reviewed)
+}
+
+func printBookings(title string, bookings []client.ReviewBooking) {
+	if len(bookings) == 0 {
+		return
+	}
+	fmt.Printf("\n%s:\n", title)
+	for i, b := range bookings {
+		fmt.Printf("  %d. %s on %s\n", i+1, b.ProjectName,
+			b.Start.Format("2006-01-02 15:04"))
+		fmt.Printf("     Verifier: %s | Status: %s | %s\n", b.VerifierLogin, b.Status, formatOnline(b.IsOnline, b.VCLink))
+		fmt.Printf("     SlotID: %s | BookingID: %s\n", b.SlotID, b.ID)
+	}
 }
 
 func addReviewSlotCmd(ctx context.Context, c *client.Client) {
-	if len(os.Args) < 5 {
-		fmt.Println("Usage: client review-slots add <start> <end>")
+	fs := newFlagSet("add", "client review-slots add [--no-validate] [--allow-duplicate] [--blackout clip|reject|off] [--conflicts kind=action,...] <start> <end>")
+	noValidate := fs.Bool("no-validate", false, "skip client-side slot validation")
+	allowDuplicate := fs.Bool("allow-duplicate", false, "add the whole interval even where slots already cover it")
+	blackoutMode := fs.String("blackout", "", "how to handle blackouts: clip, reject or off (default: mode from blackouts.json)")
+	conflicts := fs.String("conflicts", "", conflictsUsage)
+	fs.Parse(os.Args[3:])
+
+	if fs.NArg() < 2 {
+		fmt.Println("Usage: client review-slots add [--no-validate] [--allow-duplicate] [--blackout clip|reject|off] [--conflicts kind=action,...] <start> <end>")
 		fmt.Println("Example: client review-slots add '2025-01-15 14:00' '2025-01-15 14:30'")
 		os.Exit(1)
 	}
 
-	startStr := os.Args[3]
-	endStr := os.Args[4]
+	startStr := fs.Arg(0)
+	endStr := fs.Arg(1)
 
 	start, err := parseDateTime(startStr)
 	if err != nil {
@@ -406,32 +563,85 @@ func addReviewSlotCmd(ctx context.Context, c *client.Client) {
 
 	fmt.Printf("Adding review slot: %s - %s\n", start.Format("2006-01-02 15:04"), end.Format("15:04"))
 
-	slots, err := c.AddReviewSlot(ctx, start, end)
+	c.SetSlotValidation(!*noValidate)
+	if err := applyConflicts(c, *conflicts); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	useBlackouts(c, *blackoutMode)
+	parts, err := allowedParts(*blackoutMode, start, end)
 	if err != nil {
-		log.Fatalf("Error adding review slot: %v", err)
+		fatalSlotError("Error adding review slot", err)
 	}
 
-	fmt.Println("\nReview slot added successfully!")
-	if len(slots) > 0 {
-		fmt.Println("Created slots:")
-		for i, s := range slots {
-			fmt.Printf("  %d. ID: %s\n", i+1, s.ID)
-			fmt.Printf("     Type: %s\n", s.Type)
-			fmt.Printf("     Time: %s - %s\n", s.Start.Format("2006-01-02 15:04"), s.End.Format("15:04"))
+	if *allowDuplicate {
+		var slots []client.ReviewSlot
+		for _, iv := range parts {
+			added, err := c.AddReviewSlot(ctx, iv.Start, iv.End)
+			slots = append(slots, added...)
+			if err != nil {
+				printSlots("Created slots:", slots)
+				fatalSlotError("Error adding review slot", err)
+			}
 		}
+		fmt.Println("\nReview slot added successfully!")
+		printSlots("Created slots:", slots)
+		return
+	}
+
+	// Only add what existing slots do not cover yet, so that running the
+	// same command twice does not create duplicates
+	var added, extended, covering []client.ReviewSlot
+	for _, iv := range parts {
+		res, err := c.EnsureReviewSlot(ctx, iv.Start, iv.End)
+		added = append(added, res.Added...)
+		extended = append(extended, res.Extended...)
+		covering = append(covering, res.Slots...)
+		if err != nil {
+			printSlots("Created slots:", added)
+			printSlots("Extended slots:", extended)
+			fatalSlotError("Error adding review slot", err)
+		}
+	}
+
+	if len(added) == 0 && len(extended) == 0 {
+		fmt.Println("\nThe interval is already covered, nothing was added. Use --allow-duplicate to add it anyway.")
+	} else {
+		fmt.Println("\nReview slot added successfully!")
+		printSlots("Created slots:", added)
+		printSlots("Extended slots:", extended)
+	}
+	printSlots("Slots covering the interval:", covering)
+}
+
+// printSlots prints a titled list of slots, or nothing if there are none
+func printSlots(title string, slots []client.ReviewSlot) {
+	if len(slots) == 0 {
+		return
+	}
+	fmt.Println(title)
+	for i, s := range slots {
+		fmt.Printf("  %d. ID: %s\n", i+1, s.ID)
+		fmt.Printf("     Type: %s | Role: %s\n", s.Type, s.Role)
+		fmt.Printf("     Time: %s - %s\n", s.Start.Format("2006-01-02 15:04"), s.End.Format("15:04"))
 	}
 }
 
 func updateReviewSlotCmd(ctx context.Context, c *client.Client) {
-	if len(os.Args) < 6 {
-		fmt.Println("Usage: client review-slots update <slot-id> <start> <end>")
+	fs := newFlagSet("update", "client review-slots update [--no-validate] [--force] [--conflicts kind=action,...] <slot-id> <start> <end>")
+	noValidate := fs.Bool("no-validate", false, "skip client-side slot validation")
+	force := fs.Bool("force", false, "update even if the slot was booked in the meantime")
+	conflicts := fs.String("conflicts", "", conflictsUsage)
+	fs.Parse(os.Args[3:])
+
+	if fs.NArg() < 3 {
+		fmt.Println("Usage: client review-slots update [--no-validate] [--force] [--conflicts kind=action,...] <slot-id> <start> <end>")
 		fmt.Println("Example: client review-slots update slot-123 '2025-01-15 15:00' '2025-01-15 15:30'")
 		os.Exit(1)
 	}
 
-	slotID := os.Args[3]
-	startStr := os.Args[4]
-	endStr := os.Args[5]
+	slotID := fs.Arg(0)
+	startStr := fs.Arg(1)
+	endStr := fs.Arg(2)
 
 	start, err := parseDateTime(startStr)
 	if err != nil {
@@ -449,14 +659,27 @@ func updateReviewSlotCmd(ctx context.Context, c *client.Client) {
 
 	fmt.Printf("Updating review slot %s: %s - %s\n", slotID, start.Format("2006-01-02 15:04"), end.Format("15:04"))
 
-	slot, err := c.UpdateReviewSlot(ctx, slotID, start, end)
+	c.SetSlotValidation(!*noValidate)
+	if err := applyConflicts(c, *conflicts); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	var slot *client.ReviewSlot
+	if *force {
+		slot, err = c.UpdateReviewSlot(ctx, slotID, start, end)
+	} else {
+		var current *client.ReviewSlot
+		current, err = lookupReviewSlot(ctx, c, slotID)
+		if err == nil {
+			slot, err = c.GuardedUpdateReviewSlot(ctx, *current, start, end)
+		}
+	}
 	if err != nil {
-		log.Fatalf("Error updating review slot: %v", err)
+		fatalSlotError("Error updating review slot", err)
 	}
 
 	fmt.Println("\nReview slot updated successfully!")
 	fmt.Printf("  ID: %s\n", slot.ID)
-	fmt.Printf("  Type: %s\n", slot.Type)
+	fmt.Printf("  Type: %s | Role: %s\n", slot.Type, slot.Role)
 	fmt.Printf("  Time: %s - %s\n", slot.Start.Format("2006-01-02 15:04"), slot.End.Format("15:04"))
 	if slot.School != "" {
 		fmt.Printf("  School: %s\n", slot.School)
@@ -464,19 +687,32 @@ func updateReviewSlotCmd(ctx context.Context, c *client.Client) {
 }
 
 func removeReviewSlotCmd(ctx context.Context, c *client.Client) {
-	if len(os.Args) < 4 {
-		fmt.Println("Usage: client review-slots remove <slot-id>")
+	fs := newFlagSet("remove", "client review-slots remove [--force] <slot-id>")
+	force := fs.Bool("force", false, "remove even if the slot was booked in the meantime")
+	args := parseArgs(fs, os.Args[3:])
+
+	if len(args) < 1 {
+		fmt.Println("Usage: client review-slots remove [--force] <slot-id>")
 		fmt.Println("Example: client review-slots remove slot-123")
 		os.Exit(1)
 	}
 
-	slotID := os.Args[3]
+	slotID := args[0]
 
 	fmt.Printf("Removing review slot: %s\n", slotID)
 
-	err := c.RemoveReviewSlot(ctx, slotID)
+	var err error
+	if *force {
+		err = c.RemoveReviewSlot(ctx, slotID)
+	} else {
+		var slot *client.ReviewSlot
+		slot, err = lookupReviewSlot(ctx, c, slotID)
+		if err == nil {
+			err = c.GuardedRemoveReviewSlot(ctx, *slot)
+		}
+	}
 	if err != nil {
-		log.Fatalf("Error removing review slot: %v", err)
+		fatalSlotError("Error removing review slot", err)
 	}
 
 	fmt.Println("Review slot removed successfully!")
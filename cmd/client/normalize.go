@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func normalizeReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("normalize", "client review-slots normalize [--days N] [--dry-run]")
+	days := fs.Int("days", 14, "how many days ahead to normalize")
+	dryRun := fs.Bool("dry-run", false, "only show which slots would be merged")
+	fs.Parse(os.Args[3:])
+
+	from := time.Now()
+	to := from.AddDate(0, 0, *days)
+
+	if *dryRun {
+		slots, _, err := c.GetReviewSlots(ctx, from, to)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		groups := client.MergeGroups(slots)
+		if len(groups) == 0 {
+			fmt.Println("Nothing to merge.")
+			return
+		}
+		for i, g := range groups {
+			printMergeGroup(i+1, g)
+		}
+		return
+	}
+
+	results, err := c.NormalizeReviewSlots(ctx, from, to)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("Nothing to merge.")
+		return
+	}
+
+	failed := 0
+	for i, r := range results {
+		printMergeGroup(i+1, r.Merged)
+		if r.Err != nil {
+			fmt.Printf("     FAILED: %v\n", r.Err)
+			failed++
+			continue
+		}
+		fmt.Printf("     Merged into %s (ID: %s)\n", formatInterval(r.Slot.Interval()), r.Slot.ID)
+	}
+
+	if failed > 0 {
+		log.Fatalf("Error: %d of %d merges failed", failed, len(results))
+	}
+}
+
+func printMergeGroup(n int, group []client.ReviewSlot) {
+	fmt.Printf("  %d. %d slots:\n", n, len(group))
+	for _, s := range group {
+		fmt.Printf("     - %s (ID: %s)\n", formatInterval(s.Interval()), s.ID)
+	}
+}
+
+func splitReviewSlotCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("split", "client review-slots split <slot-id> --every <duration> [--days N]")
+	every := fs.Duration("every", 30*time.Minute, "length of every piece")
+	days := fs.Int("days", 14, "how many days ahead to look the slot up")
+	args := parseArgs(fs, os.Args[3:])
+
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	from := time.Now()
+	slot, err := c.FindReviewSlot(ctx, args[0], from, from.AddDate(0, 0, *days))
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Splitting slot %s (%s) every %s\n", slot.ID, formatInterval(slot.Interval()), *every)
+
+	pieces, err := c.SplitReviewSlot(ctx, *slot, *every)
+	for i, p := range pieces {
+		fmt.Printf("  %d. %s (ID: %s)\n", i+1, formatInterval(p.Interval()), p.ID)
+	}
+	if err != nil {
+		fatalSlotError("Error splitting review slot", err)
+	}
+
+	fmt.Println("\nReview slot split successfully!")
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// appDirName is the directory the CLI keeps its files in
+const appDirName = "s21gql"
+
+// configPath returns the path of a file in the CLI config directory.
+// S21_CONFIG_DIR overrides the default of <user config dir>/s21gql.
+func configPath(name string) (string, error) {
+	if dir := os.Getenv("S21_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("locate config dir: %w", err)
+	}
+	return filepath.Join(dir, appDirName, name), nil
+}
+
+// dataPath returns the path of a file in the CLI data directory, where state
+// the CLI writes itself is kept. S21_DATA_DIR overrides the default of
+// $XDG_DATA_HOME/s21gql, or ~/.local/share/s21gql if that is unset.
+func dataPath(name string) (string, error) {
+	if dir := os.Getenv("S21_DATA_DIR"); dir != "" {
+		return filepath.Join(dir, name), nil
+	}
+	if dir := os.Getenv("XDG_DATA_HOME"); dir != "" {
+		return filepath.Join(dir, appDirName, name), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("locate data dir: %w", err)
+	}
+	return filepath.Join(home, ".local", "share", appDirName, name), nil
+}
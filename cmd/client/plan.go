@@ -0,0 +1,182 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/plan"
+)
+
+func planReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("plan", "client review-slots plan [--from <time>] [--to <time>] [--out <plan.json>] <desired.json|desired.csv|->")
+	var from, to dateTimeFlag
+	fs.Var(&from, "from", "start of the plan window (default: start of the first desired interval)")
+	fs.Var(&to, "to", "end of the plan window (default: end of the last desired interval)")
+	out := fs.String("out", "", "save the plan to this file for a later apply")
+	args := parseArgs(fs, os.Args[3:])
+
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	desired, err := readIntervals(args[0])
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	window, err := planWindow(desired, from, to)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	slots, bookings, err := c.GetReviewSlots(ctx, window.Start, window.End)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	p := plan.Compute(window, desired, slots, bookings)
+	printPlan(p)
+
+	if *out != "" {
+		data, err := json.MarshalIndent(p, "", "  ")
+		if err != nil {
+			log.Fatalf("Error encoding plan: %v", err)
+		}
+		if err := os.WriteFile(*out, data, 0o644); err != nil {
+			log.Fatalf("Error saving plan: %v", err)
+		}
+		fmt.Printf("\nPlan saved to %s. Run 'client review-slots apply %s' to execute it.\n", *out, *out)
+	}
+}
+
+// planWindow picks the window a plan is computed over: the explicit flags if
+// given, the span of the desired intervals otherwise
+func planWindow(desired []client.Interval, from, to dateTimeFlag) (client.Interval, error) {
+	var window client.Interval
+	merged := client.MergeIntervals(desired)
+	if len(merged) > 0 {
+		window = client.Interval{Start: merged[0].Start, End: merged[len(merged)-1].End}
+	}
+	if from.set {
+		window.Start = from.t
+	}
+	if to.set {
+		window.End = to.t
+	}
+	if window.IsEmpty() {
+		return client.Interval{}, fmt.Errorf("empty plan window: pass --from and --to when the desired set is empty")
+	}
+	return window, nil
+}
+
+func applyReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("apply", "client review-slots apply [--force] <plan.json>")
+	force := fs.Bool("force", false, "skip the re-check of every slot right before it is changed")
+	args := parseArgs(fs, os.Args[3:])
+
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	data, err := os.ReadFile(args[0])
+	if err != nil {
+		log.Fatalf("Error reading plan: %v", err)
+	}
+
+	var p plan.Plan
+	if err := json.Unmarshal(data, &p); err != nil {
+		log.Fatalf("Error decoding plan: %v", err)
+	}
+
+	slots, bookings, err := c.GetReviewSlots(ctx, p.Window.Start, p.Window.End)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if plan.Fingerprint(p.Window, slots, bookings) != p.Fingerprint {
+		log.Fatal("Error: the calendar changed since the plan was made, run 'client review-slots plan' again")
+	}
+
+	if !p.HasChanges() {
+		fmt.Println("No changes. The calendar already matches the plan.")
+		return
+	}
+
+	for i, a := range p.Actions {
+		if a.Kind == plan.ActionKeep {
+			continue
+		}
+		fmt.Printf("%s\n", formatAction(a))
+		if err := executeAction(ctx, c, a, *force); err != nil {
+			fatalSlotError(fmt.Sprintf("Error: action %d failed (actions before it were applied)", i+1), err)
+		}
+	}
+
+	fmt.Println("\nPlan applied successfully!")
+}
+
+// executeAction performs a single plan action against the API. Unless
+// forced, deleted and resized slots are re-checked right before the change
+// so that a slot booked since the fingerprint check is left alone.
+func executeAction(ctx context.Context, c *client.Client, a plan.Action, force bool) error {
+	switch a.Kind {
+	case plan.ActionDelete:
+		if force {
+			return c.RemoveReviewSlot(ctx, a.SlotID)
+		}
+		return c.GuardedRemoveReviewSlot(ctx, actionSlot(a))
+	case plan.ActionResize:
+		var err error
+		if force {
+			_, err = c.UpdateReviewSlot(ctx, a.SlotID, a.To.Start, a.To.End)
+		} else {
+			_, err = c.GuardedUpdateReviewSlot(ctx, actionSlot(a), a.To.Start, a.To.End)
+		}
+		return err
+	case plan.ActionCreate:
+		_, err := c.AddReviewSlot(ctx, a.To.Start, a.To.End)
+		return err
+	case plan.ActionKeep:
+		return nil
+	}
+	return fmt.Errorf("unknown action kind %q", a.Kind)
+}
+
+// actionSlot returns the free slot a delete or resize action applies to
+func actionSlot(a plan.Action) client.ReviewSlot {
+	return client.ReviewSlot{ID: a.SlotID, Start: a.From.Start, End: a.From.End, Type: client.SlotTypeFree}
+}
+
+// printPlan prints a plan in the style of terraform plan
+func printPlan(p *plan.Plan) {
+	fmt.Printf("Plan window: %s\n\n", formatInterval(p.Window))
+
+	for _, a := range p.Actions {
+		fmt.Println(formatAction(a))
+	}
+
+	counts := p.Counts()
+	fmt.Printf("\nPlan: %d to create, %d to resize, %d to delete, %d unchanged.\n",
+		counts[plan.ActionCreate], counts[plan.ActionResize],
+		counts[plan.ActionDelete], counts[plan.ActionKeep])
+}
+
+func formatAction(a plan.Action) string {
+	switch a.Kind {
+	case plan.ActionCreate:
+		return fmt.Sprintf("  + create  %s\n            # %s", formatInterval(*a.To), a.Reason)
+	case plan.ActionResize:
+		return fmt.Sprintf("  ~ resize  %s: %s -> %s\n            # %s", a.SlotID,
+			formatInterval(*a.From), formatInterval(*a.To), a.Reason)
+	case plan.ActionDelete:
+		return fmt.Sprintf("  - delete  %s: %s\n            # %s", a.SlotID, formatInterval(*a.From), a.Reason)
+	default:
+		return fmt.Sprintf("    keep    %s: %s\n            # %s", a.SlotID, formatInterval(*a.From), a.Reason)
+	}
+}
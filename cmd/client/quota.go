@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/guard"
+)
+
+// withdrawnStateFile keeps the slots withdrawn by the quota policy
+const withdrawnStateFile = "withdrawn.json"
+
+// quotaFlags registers the review cap flags shared by quota and guard
+func quotaFlags(fs *flag.FlagSet) (perDay, perWeek *int) {
+	perDay = fs.Int("per-day", 0, "most reviews to conduct per day, 0 for no cap")
+	perWeek = fs.Int("per-week", 0, "most reviews to conduct per week, 0 for no cap")
+	return perDay, perWeek
+}
+
+// newQuota builds the quota policy for the current user
+func newQuota(ctx context.Context, c *client.Client, perDay, perWeek int) (guard.Quota, error) {
+	user, err := c.GetCurrentUser(ctx)
+	if err != nil {
+		return guard.Quota{}, fmt.Errorf("get current user: %w", err)
+	}
+	path, err := dataPath(withdrawnStateFile)
+	if err != nil {
+		return guard.Quota{}, err
+	}
+	return guard.NewQuota(perDay, perWeek, user.User.GetCurrentUser.Login, path), nil
+}
+
+func quotaCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("quota", "client review-slots quota [--per-day N] [--per-week N] [--enforce]")
+	perDay, perWeek := quotaFlags(fs)
+	enforce := fs.Bool("enforce", false, "withdraw or restore free slots according to the caps")
+	fs.Parse(os.Args[3:])
+
+	policy, err := newQuota(ctx, c, *perDay, *perWeek)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if *enforce {
+		if *perDay <= 0 && *perWeek <= 0 {
+			log.Fatal("Error: --enforce needs --per-day or --per-week")
+		}
+		if err := guard.RunOnce(ctx, c, []guard.Policy{policy}, logAction); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	now := time.Now()
+	_, bookings, err := c.GetReviewSlots(ctx, now.AddDate(0, 0, -7), now.Add(policy.Horizon))
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Reviews conducted by %s:\n", policy.Login)
+	for _, u := range policy.Usage(now, bookings) {
+		label := u.Start.Format("Mon 2006-01-02")
+		if u.Period == guard.PeriodWeek {
+			label = "week of " + u.Start.Format("2006-01-02")
+		}
+		limit := "no cap"
+		if u.Cap > 0 {
+			limit = fmt.Sprintf("cap %d", u.Cap)
+		}
+		status := ""
+		if u.Full() {
+			status = "  FULL"
+		}
+		fmt.Printf("  %-22s %3d  (%s)%s\n", label, u.Count, limit, status)
+	}
+
+	withdrawn, err := guard.LoadWithdrawn(policy.StatePath)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(withdrawn) > 0 {
+		fmt.Printf("\nWithdrawn slots waiting to be restored: %d\n", len(withdrawn))
+		for _, w := range withdrawn {
+			fmt.Printf("  - %s  # %s\n", formatInterval(w.Slot), w.Reason)
+		}
+	}
+}
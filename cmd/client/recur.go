@@ -0,0 +1,256 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/recur"
+)
+
+// recurRulesFile is the file recurring rules are stored in
+const recurRulesFile = "recur.json"
+
+func handleRecur(ctx context.Context, c *client.Client) {
+	if len(os.Args) < 4 {
+		printRecurUsage()
+		os.Exit(1)
+	}
+
+	path, err := configPath(recurRulesFile)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	switch os.Args[3] {
+	case "add":
+		addRecurCmd(path)
+	case "list", "ls":
+		listRecurCmd(path)
+	case "remove", "rm":
+		removeRecurCmd(path)
+	case "expand":
+		expandRecurCmd(ctx, c, path)
+	default:
+		fmt.Printf("Unknown recur command: %s\n", os.Args[3])
+		printRecurUsage()
+		os.Exit(1)
+	}
+}
+
+func printRecurUsage() {
+	fmt.Println("Usage: client review-slots recur <command>")
+	fmt.Println("\nCommands:")
+	fmt.Println("  add --rule <RRULE> --duration <d> [--start T] [--tz Zone] [--exdate D]... [--name N]")
+	fmt.Println("                       - Add a recurring availability rule")
+	fmt.Println("                        Supported RRULE parts: FREQ (DAILY, WEEKLY), INTERVAL, BYDAY, BYHOUR,")
+	fmt.Println("                        BYMINUTE, COUNT, UNTIL")
+	fmt.Println("  list|ls              - List rules and their next occurrences")
+	fmt.Println("  remove|rm <id>       - Remove a rule")
+	fmt.Println("  expand [--days N] [--create] - Show (or create) the slots the rules produce")
+	fmt.Println("\nExamples:")
+	fmt.Println("  client review-slots recur add --rule 'FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=18' --duration 2h")
+	fmt.Println("  client review-slots recur add --rule 'FREQ=DAILY;BYHOUR=10' --duration 1h --exdate 2025-01-20")
+	fmt.Println("  client review-slots recur expand --days 14 --create")
+}
+
+func addRecurCmd(path string) {
+	fs := newFlagSet("recur add", "client review-slots recur add --rule <RRULE> --duration <d> [flags]")
+	rule := fs.String("rule", "", "recurrence rule, e.g. FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=18")
+	duration := fs.Duration("duration", 0, "length of every occurrence, e.g. 2h")
+	var start dateTimeFlag
+	fs.Var(&start, "start", "first day the rule applies (default: today)")
+	tz := fs.String("tz", "", "IANA time zone BYHOUR is interpreted in (default: local zone)")
+	name := fs.String("name", "", "optional label for the rule")
+	var exdates stringListFlag
+	fs.Var(&exdates, "exdate", "exception as YYYY-MM-DD or 'YYYY-MM-DD HH:MM', repeatable")
+	fs.Parse(os.Args[4:])
+
+	if *rule == "" || *duration == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	rules, err := recur.LoadRules(path)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	r := recur.Rule{
+		ID:       recur.NextID(rules),
+		Name:     *name,
+		RRule:    strings.ToUpper(*rule),
+		Duration: recur.Duration(*duration),
+		TZ:       *tz,
+		ExDates:  exdates,
+	}
+
+	loc := time.Local
+	if r.TZ != "" {
+		if loc, err = time.LoadLocation(r.TZ); err != nil {
+			log.Fatalf("Error: invalid time zone %q: %v", r.TZ, err)
+		}
+	}
+	// parseDateTime yields UTC, but the start is a wall-clock time in the rule's zone
+	s := time.Now().In(loc)
+	s = time.Date(s.Year(), s.Month(), s.Day(), 0, 0, 0, 0, loc)
+	if start.set {
+		s = time.Date(start.t.Year(), start.t.Month(), start.t.Day(), start.t.Hour(), start.t.Minute(), 0, 0, loc)
+	}
+	r.Start = s
+
+	if err := r.Validate(); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	rules = append(rules, r)
+	if err := recur.SaveRules(path, rules); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Rule %d added.\n", r.ID)
+	printRuleOccurrences(r, 3)
+}
+
+func listRecurCmd(path string) {
+	rules, err := recur.LoadRules(path)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	if len(rules) == 0 {
+		fmt.Println("No recurring rules. Add one with 'client review-slots recur add'.")
+		return
+	}
+
+	fmt.Printf("Recurring rules (%s):\n", path)
+	for _, r := range rules {
+		fmt.Printf("\n  %d. %s", r.ID, r.RRule)
+		if r.Name != "" {
+			fmt.Printf(" (%s)", r.Name)
+		}
+		fmt.Printf("\n     Duration: %s | Since: %s", time.Duration(r.Duration), r.Start.Format("2006-01-02"))
+		if r.TZ != "" {
+			fmt.Printf(" | TZ: %s", r.TZ)
+		}
+		fmt.Println()
+		if len(r.ExDates) > 0 {
+			fmt.Printf("     Except: %s\n", strings.Join(r.ExDates, ", "))
+		}
+		printRuleOccurrences(r, 3)
+	}
+}
+
+// printRuleOccurrences prints the next n occurrences of a rule
+func printRuleOccurrences(r recur.Rule, n int) {
+	now := time.Now()
+	// Four weeks cover the next few occurrences of any daily or weekly rule
+	intervals, err := r.Expand(now, now.AddDate(0, 0, 28))
+	if err != nil {
+		fmt.Printf("     Error: %v\n", err)
+		return
+	}
+	if len(intervals) == 0 {
+		fmt.Println("     No upcoming occurrences")
+		return
+	}
+	if len(intervals) > n {
+		intervals = intervals[:n]
+	}
+	fmt.Println("     Next:")
+	for _, iv := range intervals {
+		fmt.Printf("       %s\n", formatInterval(iv))
+	}
+}
+
+func removeRecurCmd(path string) {
+	if len(os.Args) < 5 {
+		fmt.Println("Usage: client review-slots recur remove <id>")
+		os.Exit(1)
+	}
+
+	id, err := strconv.Atoi(os.Args[4])
+	if err != nil {
+		log.Fatalf("Error: invalid rule ID %q", os.Args[4])
+	}
+
+	rules, err := recur.LoadRules(path)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	kept := rules[:0]
+	for _, r := range rules {
+		if r.ID != id {
+			kept = append(kept, r)
+		}
+	}
+	if len(kept) == len(rules) {
+		log.Fatalf("Error: rule %d not found", id)
+	}
+
+	if err := recur.SaveRules(path, kept); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	fmt.Printf("Rule %d removed.\n", id)
+}
+
+func expandRecurCmd(ctx context.Context, c *client.Client, path string) {
+	fs := newFlagSet("recur expand", "client review-slots recur expand [--days N] [--create]")
+	days := fs.Int("days", 7, "horizon in days, starting now")
+	create := fs.Bool("create", false, "add review slots for the expanded intervals not covered yet")
+	fs.Parse(os.Args[4:])
+
+	rules, err := recur.LoadRules(path)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	from := time.Now()
+	to := from.AddDate(0, 0, *days)
+	intervals, err := recur.ExpandAll(rules, from, to)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Expanded %d rules over the next %d days: %d intervals\n", len(rules), *days, len(intervals))
+	for i, iv := range intervals {
+		fmt.Printf("  %d. %s\n", i+1, formatInterval(iv))
+	}
+
+	if !*create || len(intervals) == 0 {
+		return
+	}
+
+	// Only add what existing slots do not cover yet, so that expanding the
+	// same rules again does not create duplicates
+	fmt.Println("\nCreating review slots...")
+	failed := 0
+	for _, iv := range intervals {
+		res, err := c.EnsureReviewSlot(ctx, iv.Start, iv.End)
+		if err != nil {
+			fmt.Printf("  FAIL %s: %v\n", formatInterval(iv), err)
+			failed++
+			continue
+		}
+		if !res.Changed() {
+			fmt.Printf("  SKIP %s (already covered)\n", formatInterval(iv))
+			continue
+		}
+		for _, s := range res.Added {
+			fmt.Printf("  OK   %s (ID: %s)\n", formatInterval(s.Interval()), s.ID)
+		}
+		for _, s := range res.Extended {
+			fmt.Printf("  OK   %s (ID: %s, extended)\n", formatInterval(s.Interval()), s.ID)
+		}
+	}
+
+	if failed > 0 {
+		log.Fatalf("Error: %d of %d intervals could not be added", failed, len(intervals))
+	}
+}
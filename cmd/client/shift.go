@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func shiftReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("shift", "client review-slots shift [--conflicts kind=action,...] --from <time> --to <time> --by <duration>")
+	var from, to dateTimeFlag
+	fs.Var(&from, "from", "move free slots starting at or after this time")
+	fs.Var(&to, "to", "move free slots starting before this time")
+	by := fs.Duration("by", 0, "offset to move the slots by, e.g. +1h or -30m")
+	conflicts := fs.String("conflicts", "", conflictsUsage)
+	fs.Parse(os.Args[3:])
+
+	if !from.set || !to.set || *by == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if !to.t.After(from.t) {
+		log.Fatal("Error: --to must be after --from")
+	}
+	if err := applyConflicts(c, *conflicts); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	results, err := c.ShiftReviewSlots(ctx, from.t, to.t, *by)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(results) == 0 {
+		fmt.Println("No free slots in the range.")
+		return
+	}
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("  ! %s (ID: %s) not moved: %v\n", formatInterval(r.Slot.Interval()), r.Slot.ID, r.Err)
+			failed++
+			continue
+		}
+		fmt.Printf("  ~ %s -> %s (ID: %s)\n", formatInterval(r.Slot.Interval()), formatInterval(r.Moved.Interval()), r.Moved.ID)
+	}
+
+	fmt.Printf("\nMoved %d of %d free slots by %s.\n", len(results)-failed, len(results), *by)
+	if failed > 0 {
+		log.Fatalf("Error: %d slots could not be moved", failed)
+	}
+}
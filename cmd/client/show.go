@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func showBookingCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("show", "client review-slots show <booking-id> [--days N]")
+	days := fs.Int("days", 14, "how many days ahead to look the booking up")
+	args := parseArgs(fs, os.Args[3:])
+
+	if len(args) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	now := time.Now()
+	b, err := c.FindReviewBooking(ctx, args[0], now.Add(-24*time.Hour), now.AddDate(0, 0, *days))
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Printf("Booking %s\n", b.ID)
+	fmt.Printf("  Project:    %s\n", b.ProjectName)
+	if b.TaskTitle != "" && b.TaskTitle != b.ProjectName {
+		fmt.Printf("  Task:       %s\n", b.TaskTitle)
+	}
+	if b.AssignmentType != "" {
+		fmt.Printf("  Assignment: %s\n", b.AssignmentType)
+	}
+	if b.Cookies > 0 {
+		fmt.Printf("  Cookies:    %d\n", b.Cookies)
+	}
+	fmt.Printf("  Time:       %s\n", formatInterval(b.Interval()))
+	fmt.Printf("  Where:      %s\n", formatOnline(b.IsOnline, b.VCLink))
+	fmt.Printf("  Status:     %s\n", b.Status)
+	fmt.Printf("  Role:       %s\n", b.Role)
+	fmt.Printf("  Verifier:   %s\n", b.VerifierLogin)
+	fmt.Printf("  Slot ID:    %s\n", b.SlotID)
+	if b.AnswerID != "" {
+		fmt.Printf("  Answer ID:  %s\n", b.AnswerID)
+	}
+
+	if len(b.Reviewees) > 0 {
+		fmt.Println("\nReviewees:")
+		printParticipants(b.Reviewees)
+	}
+	if len(b.Team) > 0 {
+		name := b.TeamName
+		if name == "" {
+			name = "Team"
+		}
+		fmt.Printf("\n%s (%d members):\n", name, len(b.Team))
+		printParticipants(b.Team)
+	}
+}
+
+func printParticipants(people []client.Participant) {
+	for _, p := range people {
+		lead := ""
+		if p.IsTeamLead {
+			lead = " (team lead)"
+		}
+		fmt.Printf("  - %s%s: level %d, %s, %d cookies, %d review points\n",
+			p.Login, lead, p.Level, p.Campus, p.Cookies, p.CodeReviewPoints)
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/journal"
+	"github.com/arseniisemenow/s21gql/pkg/stats"
+)
+
+func statsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("stats", "client stats [--days N] [--from <time>] [--to <time>] [--role verifier|verifiable|all] [--top N] [--tz Zone] [--no-history] [--json]")
+	days := fs.Int("days", 30, "how many days back to analyze")
+	var from, to dateTimeFlag
+	fs.Var(&from, "from", "start of the analyzed range (default: --days before the end)")
+	fs.Var(&to, "to", "end of the analyzed range (default: now)")
+	role := fs.String("role", string(client.RoleVerifier), "bookings to analyze: verifier (reviews we conduct), verifiable (reviews of our projects) or all")
+	top := fs.Int("top", 10, "how many reviewees to list")
+	tz := fs.String("tz", "", "IANA time zone weeks and hours are taken in (default: local zone)")
+	noHistory := fs.Bool("no-history", false, "ignore the bookings recorded in the local journal")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Parse(os.Args[2:])
+
+	opts := stats.Options{Location: time.Local, Top: *top}
+	switch *role {
+	case string(client.RoleVerifier), string(client.RoleVerifiable):
+		opts.Roles = []client.Role{client.Role(*role)}
+	case "all":
+	default:
+		log.Fatalf("Error: invalid --role %q (use verifier, verifiable or all)", *role)
+	}
+	if *tz != "" {
+		loc, err := time.LoadLocation(*tz)
+		if err != nil {
+			log.Fatalf("Error: invalid time zone %q: %v", *tz, err)
+		}
+		opts.Location = loc
+	}
+
+	end := time.Now()
+	if to.set {
+		end = to.t
+	}
+	start := end.AddDate(0, 0, -*days)
+	if from.set {
+		start = from.t
+	}
+
+	bookings, err := c.GetBookedReviews(ctx, start, end)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	// The platform forgets cancelled bookings, the journal does not
+	var cancelled []client.ReviewBooking
+	if !*noHistory {
+		j, err := openJournal()
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if j != nil {
+			entries, err := j.Read()
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			var seen []client.ReviewBooking
+			seen, cancelled = journal.Bookings(entries)
+			bookings = append(bookings, seen...)
+		}
+	}
+
+	report := stats.Analyze(bookings, cancelled, start, end, opts)
+
+	if *asJSON {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			log.Fatalf("Error encoding report: %v", err)
+		}
+		fmt.Println(string(data))
+		return
+	}
+	printReport(report, *role)
+}
+
+func printReport(r *stats.Report, role string) {
+	fmt.Printf("Reviews from %s to %s (%s)\n\n", r.From.Local().Format("2006-01-02 15:04"), r.To.Local().Format("2006-01-02 15:04"), role)
+	fmt.Printf("Reviews:     %d\n", r.Reviews)
+	fmt.Printf("Time spent:  %s\n", formatMinutes(r.TotalMinutes))
+	fmt.Printf("Cancelled:   %d (%.1f%%)\n", r.Cancelled, r.CancellationRate*100)
+
+	if r.Reviews == 0 {
+		return
+	}
+
+	printBuckets("By project", "PROJECT", r.ByProject, false)
+	printBuckets("By week", "WEEK", r.ByWeek, true)
+	printBuckets("By hour of day", "HOUR", r.ByHour, true)
+	if len(r.TopReviewees) > 0 {
+		printBuckets("Top reviewees", "LOGIN", r.TopReviewees, false)
+	}
+}
+
+// printBuckets prints a table of buckets, with a bar chart of the review
+// counts when bars is set
+func printBuckets(title, keyHeader string, buckets []stats.Bucket, bars bool) {
+	width := len(keyHeader)
+	most := 0
+	for _, b := range buckets {
+		width = max(width, len(b.Key))
+		most = max(most, b.Reviews)
+	}
+
+	fmt.Printf("\n%s:\n", title)
+	fmt.Printf("  %-*s %7s %8s\n", width, keyHeader, "REVIEWS", "TIME")
+	for _, b := range buckets {
+		line := fmt.Sprintf("  %-*s %7d %8s", width, b.Key, b.Reviews, formatMinutes(b.Minutes))
+		if bars {
+			line += "  " + strings.Repeat("#", (b.Reviews*30+most-1)/most)
+		}
+		fmt.Println(line)
+	}
+}
+
+// formatMinutes formats a duration in minutes as hours and minutes
+func formatMinutes(minutes int) string {
+	return fmt.Sprintf("%dh%02dm", minutes/60, minutes%60)
+}
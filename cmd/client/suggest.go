@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func suggestReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("suggest", "client review-slots suggest [--days N] [--from T] [--to T] [--min <d>] [--buffer <d>] [--hours HH:MM-HH:MM] [--tz Zone] [--ignore kind]... [--create]")
+	days := fs.Int("days", 7, "how many days ahead to search")
+	var from, to dateTimeFlag
+	fs.Var(&from, "from", "start of the search range (default: now)")
+	fs.Var(&to, "to", "end of the search range (default: --days from the start)")
+	minLength := fs.Duration("min", time.Hour, "shortest gap worth suggesting")
+	buffer := fs.Duration("buffer", 15*time.Minute, "time to keep free around every event")
+	hours := fs.String("hours", "", "working hours, e.g. 10:00-21:00 (default: the whole day)")
+	tz := fs.String("tz", "", "IANA time zone of the working hours (default: local zone)")
+	var ignore stringListFlag
+	fs.Var(&ignore, "ignore", "event kind that does not count as busy: exam, activity, penalty, review, reviewed, slot or other (repeatable)")
+	create := fs.Bool("create", false, "create a review slot for every suggestion")
+	fs.Parse(os.Args[3:])
+
+	constraints := client.FreeTimeConstraints{MinLength: *minLength, Buffer: *buffer, Location: time.Local}
+	if *tz != "" {
+		loc, err := time.LoadLocation(*tz)
+		if err != nil {
+			log.Fatalf("Error: invalid time zone %q: %v", *tz, err)
+		}
+		constraints.Location = loc
+	}
+	if *hours != "" {
+		var err error
+		constraints.DayStart, constraints.DayEnd, err = parseWorkingHours(*hours)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+	for _, s := range ignore {
+		k, err := client.ParseEventKind(s)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		constraints.Ignore = append(constraints.Ignore, k)
+	}
+
+	start := time.Now()
+	if from.set {
+		start = from.t
+	}
+	end := start.AddDate(0, 0, *days)
+	if to.set {
+		end = to.t
+	}
+
+	gaps, err := c.FindFreeTime(ctx, start, end, constraints)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(gaps) == 0 {
+		fmt.Println("No free time found.")
+		return
+	}
+
+	fmt.Printf("Suggested review slots (%d):\n", len(gaps))
+	for i, g := range gaps {
+		fmt.Printf("  %d. %s (%s)\n", i+1, formatInterval(g), g.Duration())
+	}
+
+	if *create {
+		fmt.Println()
+		printBatchResults("add", c.AddReviewSlots(ctx, gaps, client.DefaultBatchConcurrency))
+	}
+}
+
+// parseWorkingHours parses HH:MM-HH:MM into offsets from midnight. The end
+// may be 24:00.
+func parseWorkingHours(s string) (time.Duration, time.Duration, error) {
+	parts := strings.SplitN(s, "-", 2)
+	if len(parts) != 2 {
+		return 0, 0, fmt.Errorf("invalid working hours %q (use HH:MM-HH:MM)", s)
+	}
+
+	var offsets [2]time.Duration
+	for i, p := range parts {
+		p = strings.TrimSpace(p)
+		if p == "24:00" {
+			offsets[i] = 24 * time.Hour
+			continue
+		}
+		t, err := time.Parse("15:04", p)
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid working hours %q (use HH:MM-HH:MM)", s)
+		}
+		offsets[i] = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if offsets[1] <= offsets[0] {
+		return 0, 0, fmt.Errorf("invalid working hours %q: end must be after start", s)
+	}
+	return offsets[0], offsets[1], nil
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/journal"
+)
+
+func undoReviewSlotsCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("undo", "client review-slots undo [--dry-run] [--no-validate] [--conflicts kind=action,...] [n]")
+	dryRun := fs.Bool("dry-run", false, "only show which operations would be reversed")
+	noValidate := fs.Bool("no-validate", false, "skip client-side slot validation")
+	conflicts := fs.String("conflicts", "", conflictsUsage)
+	args := parseArgs(fs, os.Args[3:])
+
+	if len(args) > 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	n := 1
+	if len(args) == 1 {
+		var err error
+		if n, err = strconv.Atoi(args[0]); err != nil || n < 1 {
+			log.Fatalf("Error: invalid number of operations %q", args[0])
+		}
+	}
+
+	c.SetSlotValidation(!*noValidate)
+	if err := applyConflicts(c, *conflicts); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	j, err := openJournal()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if j == nil {
+		log.Fatal("Error: undo needs the journal, which is disabled by S21_JOURNAL=off")
+	}
+	entries, err := j.Read()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	ops := journal.Undoable(entries, n)
+	if len(ops) == 0 {
+		fmt.Println("Nothing to undo.")
+		return
+	}
+	if len(ops) < n {
+		fmt.Printf("Only %d operation(s) can be undone.\n", len(ops))
+	}
+
+	undoer := journal.NewUndoer(c, entries)
+	for i, e := range ops {
+		fmt.Printf("  %d. %s\n", i+1, describeUndo(*e.Mutation))
+		if *dryRun {
+			continue
+		}
+
+		// Record the reversal as an undo of e, so it is neither undone
+		// again nor undone itself by the next undo
+		key := e.Key()
+		c.SetMutationObserver(func(m client.Mutation) {
+			undo := journal.MutationEntry(m)
+			undo.Undoes = key
+			if err := j.Append(undo); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		})
+		undoer.Gone = func(s client.ReviewSlot) {
+			fmt.Printf("     slot %s was already removed\n", s.ID)
+			if err := j.Append(journal.GoneEntry(s, key, time.Now())); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+
+		if err := undoer.Undo(ctx, *e.Mutation); err != nil {
+			prefix := fmt.Sprintf("Error: undo %d failed", i+1)
+			if i > 0 {
+				prefix += " (the undos before it were applied)"
+			}
+			if errors.Is(err, client.ErrSlotBooked) {
+				log.Fatalf("%s: refusing to touch a slot booked since: %v", prefix, err)
+			}
+			fatalSlotError(prefix, err)
+		}
+	}
+
+	if !*dryRun {
+		fmt.Println("\nUndone successfully!")
+	}
+}
+
+// describeUndo says what undoing a mutation does
+func describeUndo(m client.Mutation) string {
+	when := m.At.Local().Format("2006-01-02 15:04:05")
+	switch m.Op {
+	case client.MutationAdd:
+		ids := make([]string, len(m.After))
+		for i, s := range m.After {
+			ids[i] = s.ID
+		}
+		return fmt.Sprintf("remove slot(s) %s added at %s", strings.Join(ids, ", "), when)
+	case client.MutationUpdate:
+		if m.Before == nil {
+			return fmt.Sprintf("restore slot %s updated at %s", m.SlotID, when)
+		}
+		return fmt.Sprintf("move slot %s updated at %s back to %s", m.SlotID, when, formatInterval(m.Before.Interval()))
+	case client.MutationRemove:
+		if m.Before == nil {
+			return fmt.Sprintf("recreate slot %s removed at %s", m.SlotID, when)
+		}
+		return fmt.Sprintf("recreate slot %s removed at %s: %s", m.SlotID, when, formatInterval(m.Before.Interval()))
+	}
+	return fmt.Sprintf("%s of slot %s at %s", m.Op, m.SlotID, when)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"context"
+	"log"
+	"os"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/journal"
+	"github.com/arseniisemenow/s21gql/pkg/notify"
+)
+
+// hooksConfigFile is the file booking event hooks are read from
+const hooksConfigFile = "hooks.json"
+
+func watchCmd(ctx context.Context, c *client.Client) {
+	fs := newFlagSet("watch", "client watch [--interval <duration>] [--days N] [--hooks <hooks.json>]")
+	interval := fs.Duration("interval", time.Minute, "how often to check the calendar")
+	days := fs.Int("days", 14, "how many days ahead to watch")
+	hooksPath := fs.String("hooks", "", "hooks file (default: hooks.json in the config dir)")
+	fs.Parse(os.Args[2:])
+
+	if *hooksPath == "" {
+		path, err := configPath(hooksConfigFile)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		*hooksPath = path
+	}
+	hooks, err := notify.LoadHooks(*hooksPath)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	j, err := openJournal()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if j != nil {
+		compactJournal(j)
+	}
+
+	dispatcher := notify.NewDispatcher(hooks)
+
+	log.Printf("Watching bookings for the next %d days every %s with %d hooks, press Ctrl+C to stop",
+		*days, *interval, len(hooks))
+
+	for ch := range c.WatchUpcoming(ctx, time.Duration(*days)*24*time.Hour, *interval) {
+		if ch.Type == client.WatchFailed {
+			log.Printf("Error: %v", ch.Err)
+			continue
+		}
+		if j != nil {
+			if err := j.Append(journal.ChangeEntry(ch)); err != nil {
+				log.Printf("Warning: %v", err)
+			}
+		}
+		e, ok := notify.BookingEvent(ch)
+		if !ok {
+			continue
+		}
+		log.Printf("%s: %s by %s at %s (online: %v)", e.Type, e.ProjectName, e.VerifierLogin,
+			e.Start.Format("2006-01-02 15:04"), e.IsOnline)
+		dispatchCtx, cancel := context.WithTimeout(ctx, requestTimeout)
+		if err := dispatcher.Dispatch(dispatchCtx, e); err != nil {
+			log.Printf("Error: %v", err)
+		}
+		cancel()
+	}
+	log.Println("Watch stopped")
+}
@@ -0,0 +1,285 @@
+// Package blackout keeps review slots out of times when we must never be
+// available: recurring quiet hours such as nights, fixed ranges such as a
+// vacation week, and busy events imported from .ics files.
+package blackout
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Mode decides what happens to a slot that intersects a blackout
+type Mode string
+
+const (
+	// ModeClip drops the blacked-out parts and keeps the rest of the slot
+	ModeClip Mode = "clip"
+	// ModeReject refuses the whole slot
+	ModeReject Mode = "reject"
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// QuietHours is a daily time-of-day range, such as 22:00-08:00. A range
+// whose end is not after its start runs past midnight.
+type QuietHours struct {
+	Days   []string `json:"days,omitempty"` // MO..SU the range starts on, every day if empty
+	From   string   `json:"from"`           // HH:MM
+	To     string   `json:"to"`             // HH:MM
+	Reason string   `json:"reason,omitempty"`
+}
+
+// Range is a fixed blackout, such as a vacation
+type Range struct {
+	Start  string `json:"start"` // YYYY-MM-DD or YYYY-MM-DD HH:MM
+	End    string `json:"end"`   // exclusive, same formats as Start
+	Reason string `json:"reason,omitempty"`
+}
+
+// Config is the blackout configuration file
+type Config struct {
+	Mode   Mode         `json:"mode,omitempty"` // clip if empty
+	TZ     string       `json:"tz,omitempty"`   // zone times are interpreted in, local zone if empty
+	Quiet  []QuietHours `json:"quiet,omitempty"`
+	Ranges []Range      `json:"ranges,omitempty"`
+	ICS    []string     `json:"ics,omitempty"` // .ics files whose events are busy time
+
+	// Warn receives the .ics events that were skipped because they could
+	// not be read, such as ones with an unsupported RRULE
+	Warn func(error) `json:"-"`
+}
+
+// Block is a concrete blacked-out interval
+type Block struct {
+	client.Interval
+	Reason string
+}
+
+// LoadConfig reads a configuration file. A missing file has no blackouts.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Config{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read blackout config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("decode blackout config: %w", err)
+	}
+	if cfg.Mode != "" && cfg.Mode != ModeClip && cfg.Mode != ModeReject {
+		return nil, fmt.Errorf("invalid blackout mode %q (use clip or reject)", cfg.Mode)
+	}
+	return &cfg, nil
+}
+
+func (c *Config) location() (*time.Location, error) {
+	if c.TZ == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(c.TZ)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", c.TZ, err)
+	}
+	return loc, nil
+}
+
+// Blocks returns every blackout that intersects [from, to), clipped to it
+// and sorted by start time
+func (c *Config) Blocks(from, to time.Time) ([]Block, error) {
+	loc, err := c.location()
+	if err != nil {
+		return nil, err
+	}
+	window := client.Interval{Start: from, End: to}
+
+	var blocks []Block
+	addBlock := func(iv client.Interval, reason string) {
+		if clipped, ok := iv.Intersect(window); ok {
+			blocks = append(blocks, Block{Interval: clipped, Reason: reason})
+		}
+	}
+
+	for _, q := range c.Quiet {
+		fromMin, err := parseClock(q.From)
+		if err != nil {
+			return nil, err
+		}
+		toMin, err := parseClock(q.To)
+		if err != nil {
+			return nil, err
+		}
+		days := make(map[time.Weekday]bool)
+		for _, d := range q.Days {
+			wd, ok := weekdays[strings.ToUpper(d)]
+			if !ok {
+				return nil, fmt.Errorf("invalid quiet hours day %q", d)
+			}
+			days[wd] = true
+		}
+		reason := q.Reason
+		if reason == "" {
+			reason = fmt.Sprintf("quiet hours %s-%s", q.From, q.To)
+		}
+
+		// Start a day early to catch ranges that run past midnight into the window
+		f := from.In(loc).AddDate(0, 0, -1)
+		for day := time.Date(f.Year(), f.Month(), f.Day(), 0, 0, 0, 0, loc); day.Before(to); day = day.AddDate(0, 0, 1) {
+			if len(days) > 0 && !days[day.Weekday()] {
+				continue
+			}
+			// Wall clock times, so quiet hours stay put on days the clocks change
+			start := time.Date(day.Year(), day.Month(), day.Day(), fromMin/60, fromMin%60, 0, 0, loc)
+			endDay := day
+			if toMin <= fromMin {
+				endDay = day.AddDate(0, 0, 1)
+			}
+			end := time.Date(endDay.Year(), endDay.Month(), endDay.Day(), toMin/60, toMin%60, 0, 0, loc)
+			addBlock(client.Interval{Start: start, End: end}, reason)
+		}
+	}
+
+	for _, r := range c.Ranges {
+		start, err := parseRangeTime(r.Start, loc)
+		if err != nil {
+			return nil, err
+		}
+		end, err := parseRangeTime(r.End, loc)
+		if err != nil {
+			return nil, err
+		}
+		reason := r.Reason
+		if reason == "" {
+			reason = "blackout range"
+		}
+		addBlock(client.Interval{Start: start, End: end}, reason)
+	}
+
+	for _, path := range c.ICS {
+		events, skipped, err := LoadICS(path, from, to)
+		if err != nil {
+			return nil, err
+		}
+		if c.Warn != nil {
+			for _, err := range skipped {
+				c.Warn(err)
+			}
+		}
+		for _, e := range events {
+			addBlock(e.Interval, e.Reason)
+		}
+	}
+
+	sort.Slice(blocks, func(a, b int) bool { return blocks[a].Start.Before(blocks[b].Start) })
+	return blocks, nil
+}
+
+// ClipResult describes what blackouts did to a requested slot
+type ClipResult struct {
+	Requested client.Interval
+	Allowed   []client.Interval // the parts that may still be added
+	Clipped   []Block           // the parts that fall into a blackout
+}
+
+// Changed reports whether any part of the request was blacked out
+func (r *ClipResult) Changed() bool {
+	return len(r.Clipped) > 0
+}
+
+// Clip removes the blacked-out parts of a requested slot
+func Clip(req client.Interval, blocks []Block) *ClipResult {
+	res := &ClipResult{Requested: req}
+	var busy []client.Interval
+	for _, b := range blocks {
+		if part, ok := b.Intersect(req); ok {
+			res.Clipped = append(res.Clipped, Block{Interval: part, Reason: b.Reason})
+			busy = append(busy, part)
+		}
+	}
+	res.Allowed = client.SubtractIntervals([]client.Interval{req}, busy)
+	return res
+}
+
+// Error is returned when a slot intersects a blackout in reject mode, or
+// when clipping leaves nothing to add
+type Error struct {
+	Result *ClipResult
+}
+
+func (e *Error) Error() string {
+	reasons := make([]string, len(e.Result.Clipped))
+	for i, b := range e.Result.Clipped {
+		reasons[i] = fmt.Sprintf("%s - %s (%s)", b.Start.Format("2006-01-02 15:04"), b.End.Format("15:04"), b.Reason)
+	}
+	return fmt.Sprintf("slot intersects blackouts: %s", strings.Join(reasons, "; "))
+}
+
+// Apply clips a requested slot and handles the result according to mode:
+// it returns an *Error if the slot intersects a blackout in reject mode or
+// nothing is left after clipping. res.Allowed holds the parts that may be
+// added.
+func (c *Config) Apply(mode Mode, start, end time.Time) (*ClipResult, error) {
+	blocks, err := c.Blocks(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	res := Clip(client.Interval{Start: start, End: end}, blocks)
+	if res.Changed() && (mode == ModeReject || len(res.Allowed) == 0) {
+		return res, &Error{Result: res}
+	}
+	return res, nil
+}
+
+// Filter returns a client.SlotFilter that applies the blackouts in the given
+// mode to every slot the client creates or moves. Moved slots cannot be
+// clipped, so they are always handled as in ModeReject. Install it with
+// client.WithSlotFilter.
+func (c *Config) Filter(mode Mode) client.SlotFilter {
+	return func(slot client.Interval, clip bool) ([]client.Interval, error) {
+		m := mode
+		if !clip {
+			m = ModeReject
+		}
+		res, err := c.Apply(m, slot.Start, slot.End)
+		if err != nil {
+			return nil, err
+		}
+		return res.Allowed, nil
+	}
+}
+
+func parseClock(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time of day %q (use HH:MM)", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func parseRangeTime(s string, loc *time.Location) (time.Time, error) {
+	for _, f := range []string{"2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(f, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid blackout time %q (use YYYY-MM-DD or YYYY-MM-DD HH:MM)", s)
+}
@@ -0,0 +1,193 @@
+package blackout
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/recur"
+)
+
+// icsProperty is a single unfolded content line of an .ics file
+type icsProperty struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// LoadICS reads the busy events of an .ics file that intersect [from, to).
+// Events marked TRANSP:TRANSPARENT are free time and skipped. Recurring
+// events are expanded with the RRULE subset supported by package recur.
+// An event that cannot be read, such as one with an unsupported RRULE, does
+// not fail the whole file: it is left out and reported in skipped.
+func LoadICS(path string, from, to time.Time) ([]Block, []error, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, fmt.Errorf("open ics: %w", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Lines starting with whitespace continue the previous one
+		if len(line) > 0 && (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, fmt.Errorf("read ics: %w", err)
+	}
+
+	window := client.Interval{Start: from, End: to}
+	var blocks []Block
+	var skipped []error
+	var event []icsProperty
+	inEvent := false
+
+	for _, line := range lines {
+		switch line {
+		case "BEGIN:VEVENT":
+			inEvent, event = true, nil
+			continue
+		case "END:VEVENT":
+			inEvent = false
+			occurrences, err := expandEvent(event, from, to)
+			if err != nil {
+				skipped = append(skipped, fmt.Errorf("%s: skipped event: %w", path, err))
+				continue
+			}
+			for _, b := range occurrences {
+				if b.Overlaps(window) {
+					blocks = append(blocks, b)
+				}
+			}
+			continue
+		}
+		if inEvent {
+			if p, ok := parseICSLine(line); ok {
+				event = append(event, p)
+			}
+		}
+	}
+
+	return blocks, skipped, nil
+}
+
+func parseICSLine(line string) (icsProperty, bool) {
+	head, value, ok := strings.Cut(line, ":")
+	if !ok {
+		return icsProperty{}, false
+	}
+	parts := strings.Split(head, ";")
+	p := icsProperty{name: strings.ToUpper(parts[0]), params: make(map[string]string), value: value}
+	for _, param := range parts[1:] {
+		if k, v, ok := strings.Cut(param, "="); ok {
+			p.params[strings.ToUpper(k)] = strings.Trim(v, `"`)
+		}
+	}
+	return p, true
+}
+
+// expandEvent turns a VEVENT into its concrete busy intervals
+func expandEvent(props []icsProperty, from, to time.Time) ([]Block, error) {
+	var start, end time.Time
+	var tz, rrule, summary string
+	var exdates []string
+
+	for _, p := range props {
+		switch p.name {
+		case "DTSTART":
+			t, err := parseICSTime(p)
+			if err != nil {
+				return nil, err
+			}
+			start, tz = t, p.params["TZID"]
+			if strings.HasSuffix(p.value, "Z") {
+				tz = "UTC"
+			}
+		case "DTEND":
+			t, err := parseICSTime(p)
+			if err != nil {
+				return nil, err
+			}
+			end = t
+		case "RRULE":
+			rrule = p.value
+		case "EXDATE":
+			for _, v := range strings.Split(p.value, ",") {
+				t, err := parseICSTime(icsProperty{params: p.params, value: v})
+				if err != nil {
+					return nil, err
+				}
+				exdates = append(exdates, t.Format("2006-01-02 15:04"))
+			}
+		case "SUMMARY":
+			summary = p.value
+		case "TRANSP":
+			if strings.EqualFold(p.value, "TRANSPARENT") {
+				return nil, nil
+			}
+		}
+	}
+
+	if start.IsZero() {
+		return nil, nil
+	}
+	if end.IsZero() {
+		end = start.AddDate(0, 0, 1)
+	}
+	reason := "busy"
+	if summary != "" {
+		reason = "busy: " + summary
+	}
+
+	if rrule == "" {
+		return []Block{{Interval: client.Interval{Start: start, End: end}, Reason: reason}}, nil
+	}
+
+	rule := recur.Rule{
+		RRule:    rrule,
+		Duration: recur.Duration(end.Sub(start)),
+		Start:    start,
+		TZ:       tz,
+		ExDates:  exdates,
+	}
+	// Start early enough to catch occurrences that began before the window
+	intervals, err := rule.Expand(from.Add(-end.Sub(start)), to)
+	if err != nil {
+		return nil, fmt.Errorf("event %q: %w", summary, err)
+	}
+	blocks := make([]Block, len(intervals))
+	for i, iv := range intervals {
+		blocks[i] = Block{Interval: iv, Reason: reason}
+	}
+	return blocks, nil
+}
+
+// parseICSTime parses the DATE and DATE-TIME forms, honouring TZID
+func parseICSTime(p icsProperty) (time.Time, error) {
+	loc := time.Local
+	if tzid := p.params["TZID"]; tzid != "" {
+		l, err := time.LoadLocation(tzid)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+		}
+		loc = l
+	}
+
+	switch {
+	case strings.HasSuffix(p.value, "Z"):
+		return time.Parse("20060102T150405Z", p.value)
+	case p.params["VALUE"] == "DATE" || len(p.value) == 8:
+		return time.ParseInLocation("20060102", p.value, loc)
+	default:
+		return time.ParseInLocation("20060102T150405", p.value, loc)
+	}
+}
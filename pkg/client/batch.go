@@ -0,0 +1,184 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultBatchConcurrency is how many requests a batch runs at once when no
+// limit is given
+const DefaultBatchConcurrency = 4
+
+// BatchResult is the outcome of one item of a batch operation
+type BatchResult struct {
+	Index    int          // position of the item in the input
+	SlotID   string       // slot the item referred to, for removes and updates
+	Interval Interval     // requested times, for adds and updates
+	Slots    []ReviewSlot // slots created or updated by the item
+	Err      error
+}
+
+// SlotUpdate is one item of UpdateReviewSlots
+type SlotUpdate struct {
+	SlotID string
+	Interval
+}
+
+// FailedResults returns the results that carry an error
+func FailedResults(results []BatchResult) []BatchResult {
+	var failed []BatchResult
+	for _, r := range results {
+		if r.Err != nil {
+			failed = append(failed, r)
+		}
+	}
+	return failed
+}
+
+// runBatch calls do for every index in [0, n) with at most concurrency calls
+// in flight. Items not started when ctx is done get the context error.
+func runBatch(ctx context.Context, n, concurrency int, do func(ctx context.Context, i int) BatchResult) []BatchResult {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	results := make([]BatchResult, n)
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			for j := i; j < n; j++ {
+				results[j] = BatchResult{Index: j, Err: ctx.Err()}
+			}
+			wg.Wait()
+			return results
+		}
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			r := do(ctx, i)
+			r.Index = i
+			results[i] = r
+		}(i)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// AddReviewSlots creates a slot for every interval, running at most
+// concurrency requests at once (DefaultBatchConcurrency if not positive).
+// Unless slot validation is disabled, an interval overlapping an earlier one
+// in the same batch fails with a *ValidationError instead of being sent.
+func (c *Client) AddReviewSlots(ctx context.Context, intervals []Interval, concurrency int) []BatchResult {
+	results := runBatch(ctx, len(intervals), concurrency, func(ctx context.Context, i int) BatchResult {
+		iv := intervals[i]
+		var r BatchResult
+		if !c.skipSlotValidation {
+			for j := 0; j < i; j++ {
+				if intervals[j].Overlaps(iv) {
+					r.Err = &ValidationError{Slot: iv, Violations: []RuleViolation{{
+						Rule:    RuleOverlap,
+						Message: fmt.Sprintf("overlaps item %d of the batch", j+1),
+					}}}
+					return r
+				}
+			}
+		}
+		r.Slots, r.Err = c.AddReviewSlot(ctx, iv.Start, iv.End)
+		return r
+	})
+	for i := range results {
+		results[i].Interval = intervals[i]
+	}
+	return results
+}
+
+// RemoveReviewSlots removes every slot by ID, running at most concurrency
+// requests at once (DefaultBatchConcurrency if not positive). Unless force is
+// set, every slot is re-checked right before it is removed, and one booked in
+// the meantime fails with an error wrapping ErrSlotBooked instead of
+// cancelling the review.
+func (c *Client) RemoveReviewSlots(ctx context.Context, slotIDs []string, concurrency int, force bool) []BatchResult {
+	current, listErr := c.batchSlots(ctx, force)
+	results := runBatch(ctx, len(slotIDs), concurrency, func(ctx context.Context, i int) BatchResult {
+		if force {
+			return BatchResult{Err: c.RemoveReviewSlot(ctx, slotIDs[i])}
+		}
+		slot, err := batchSlot(current, listErr, slotIDs[i])
+		if err != nil {
+			return BatchResult{Err: err}
+		}
+		return BatchResult{Err: c.GuardedRemoveReviewSlot(ctx, slot)}
+	})
+	for i := range results {
+		results[i].SlotID = slotIDs[i]
+	}
+	return results
+}
+
+// UpdateReviewSlots changes the times of every slot, running at most
+// concurrency requests at once (DefaultBatchConcurrency if not positive).
+// Unless force is set, booked slots are refused as in RemoveReviewSlots.
+func (c *Client) UpdateReviewSlots(ctx context.Context, updates []SlotUpdate, concurrency int, force bool) []BatchResult {
+	current, listErr := c.batchSlots(ctx, force)
+	results := runBatch(ctx, len(updates), concurrency, func(ctx context.Context, i int) BatchResult {
+		u := updates[i]
+		var r BatchResult
+		var slot *ReviewSlot
+		if force {
+			slot, r.Err = c.UpdateReviewSlot(ctx, u.SlotID, u.Start, u.End)
+		} else {
+			var before ReviewSlot
+			if before, r.Err = batchSlot(current, listErr, u.SlotID); r.Err == nil {
+				slot, r.Err = c.GuardedUpdateReviewSlot(ctx, before, u.Start, u.End)
+			}
+		}
+		if slot != nil {
+			r.Slots = []ReviewSlot{*slot}
+		}
+		return r
+	})
+	for i := range results {
+		results[i].SlotID = updates[i].SlotID
+		results[i].Interval = updates[i].Interval
+	}
+	return results
+}
+
+// batchSlots reads every slot a batch may refer to once, so that guarded
+// batch items only need the narrow re-check of CheckSlotFree
+func (c *Client) batchSlots(ctx context.Context, force bool) (map[string]ReviewSlot, error) {
+	if force {
+		return nil, nil
+	}
+	from, to := c.slotRange(time.Now())
+	slots, _, err := c.GetReviewSlots(ctx, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("list slots: %w", err)
+	}
+	byID := make(map[string]ReviewSlot, len(slots))
+	for _, s := range slots {
+		byID[s.ID] = s
+	}
+	return byID, nil
+}
+
+// batchSlot returns the slot of a batch item as read by batchSlots
+func batchSlot(current map[string]ReviewSlot, err error, id string) (ReviewSlot, error) {
+	if err != nil {
+		return ReviewSlot{}, err
+	}
+	slot, ok := current[id]
+	if !ok {
+		return ReviewSlot{}, fmt.Errorf("%w: slot %s", ErrSlotNotFound, id)
+	}
+	return slot, nil
+}
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// EventKind classifies the calendar events that occupy time
+type EventKind string
+
+const (
+	// EventExam is an exam we are registered for
+	EventExam EventKind = "exam"
+	// EventActivity is a registered activity, such as a lecture or workshop
+	EventActivity EventKind = "activity"
+	// EventPenalty is a penalty slot we have to attend
+	EventPenalty EventKind = "penalty"
+	// EventReview is a booked review we conduct
+	EventReview EventKind = "review"
+	// EventReviewed is a review of one of our own projects
+	EventReviewed EventKind = "reviewed"
+	// EventSlot is one of our own free review slots
+	EventSlot EventKind = "slot"
+	// EventOther is any other calendar event
+	EventOther EventKind = "other"
+)
+
+// ParseEventKind parses one of the EventKind names
+func ParseEventKind(s string) (EventKind, error) {
+	switch k := EventKind(strings.ToLower(s)); k {
+	case EventExam, EventActivity, EventPenalty, EventReview, EventReviewed, EventSlot, EventOther:
+		return k, nil
+	}
+	return "", fmt.Errorf("invalid event kind %q (use exam, activity, penalty, review, reviewed, slot or other)", s)
+}
+
+// BusyEvent is a piece of the calendar that is already taken
+type BusyEvent struct {
+	Interval
+	Kind  EventKind `json:"kind"`
+	ID    string    `json:"id"`
+	Title string    `json:"title"`
+}
+
+func (e BusyEvent) String() string {
+	return fmt.Sprintf("%s %q (%s - %s)", e.Kind, e.Title,
+		e.Start.Format("2006-01-02 15:04"), e.End.Format("15:04"))
+}
+
+// BusyEvents turns calendar events into the time they occupy. Review events
+// contribute one entry per slot or booking, a review of our own project
+// when our role on it is verifiable; activities only count when we are
+// registered for them.
+func BusyEvents(events []CalendarEvent) []BusyEvent {
+	var busy []BusyEvent
+	for _, event := range events {
+		start, _ := time.Parse(time.RFC3339, event.Start)
+		end, _ := time.Parse(time.RFC3339, event.End)
+		iv := Interval{Start: start, End: end}
+
+		switch {
+		case event.StudentCodeReview != nil:
+			busy = append(busy, BusyEvent{iv, EventReviewed, event.ID, event.Description})
+		case event.EventCode == "student_check":
+			booked := make(map[string]bool)
+			for _, b := range event.Bookings {
+				bStart, _ := time.Parse(time.RFC3339, b.EventSlot.Start)
+				bEnd, _ := time.Parse(time.RFC3339, b.EventSlot.End)
+				booked[b.EventSlot.ID] = true
+				busy = append(busy, BusyEvent{Interval{Start: bStart, End: bEnd}, reviewKind(event, b.EventSlot.Event.EventUserRole), b.ID, b.Task.GoalName})
+			}
+			for _, s := range event.EventSlots {
+				if booked[s.ID] {
+					continue
+				}
+				sStart, _ := time.Parse(time.RFC3339, s.Start)
+				sEnd, _ := time.Parse(time.RFC3339, s.End)
+				kind := EventSlot
+				if s.Type != SlotTypeFree {
+					kind = reviewKind(event, s.Event.EventUserRole)
+				}
+				busy = append(busy, BusyEvent{Interval{Start: sStart, End: sEnd}, kind, s.ID, "review slot"})
+			}
+		case event.Exam != nil:
+			busy = append(busy, BusyEvent{iv, EventExam, event.ID, event.Exam.Name})
+		case event.Activity != nil:
+			if event.Activity.IsRegistered {
+				busy = append(busy, BusyEvent{iv, EventActivity, event.ID, event.Activity.Name})
+			}
+		case event.Penalty != nil:
+			busy = append(busy, BusyEvent{iv, EventPenalty, event.ID, event.Penalty.Comment})
+		default:
+			busy = append(busy, BusyEvent{iv, EventOther, event.ID, event.Description})
+		}
+	}
+	return busy
+}
+
+// reviewKind tells a review we conduct from a review of our own project
+func reviewKind(event CalendarEvent, eventUserRole string) EventKind {
+	if eventRole(event, eventUserRole) == RoleVerifiable {
+		return EventReviewed
+	}
+	return EventReview
+}
+
+// GetBusyEvents fetches the calendar and returns the time it occupies
+func (c *Client) GetBusyEvents(ctx context.Context, from, to time.Time) ([]BusyEvent, error) {
+	resp, err := c.GetCalendarEvents(ctx, from.UTC().Format("2006-01-02T15:04:05.000Z"), to.UTC().Format("2006-01-02T15:04:05.000Z"))
+	if err != nil {
+		return nil, err
+	}
+	return BusyEvents(resp.CalendarEventS21.GetMyCalendarEvents), nil
+}
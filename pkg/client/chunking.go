@@ -0,0 +1,157 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultCalendarChunk is the longest range a single calendar request covers
+const DefaultCalendarChunk = 7 * 24 * time.Hour
+
+// WithCalendarChunking sets the longest range a single calendar request
+// covers and how many of those requests run at once. A size of zero sends
+// every range in one request.
+func WithCalendarChunking(size time.Duration, concurrency int) ClientOption {
+	return func(c *Client) {
+		c.calendarChunk = size
+		c.calendarConcurrency = concurrency
+	}
+}
+
+// calendarWindows cuts [from, to) into consecutive windows of at most size
+func calendarWindows(from, to time.Time, size time.Duration) []Interval {
+	if size <= 0 || to.Sub(from) <= size {
+		return []Interval{{Start: from, End: to}}
+	}
+	return SplitInterval(Interval{Start: from, End: to}, size)
+}
+
+// fetchCalendarChunks fetches every window with at most concurrency requests
+// in flight. The first error cancels the remaining requests.
+func (c *Client) fetchCalendarChunks(ctx context.Context, windows []Interval, concurrency int) ([][]CalendarEvent, error) {
+	if concurrency <= 0 {
+		concurrency = DefaultBatchConcurrency
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make([][]CalendarEvent, len(windows))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for i, w := range windows {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func(i int, w Interval) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			resp, err := c.fetchCalendarEvents(ctx, w.Start.UTC().Format("2006-01-02T15:04:05.000Z"), w.End.UTC().Format("2006-01-02T15:04:05.000Z"))
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			results[i] = resp.CalendarEventS21.GetMyCalendarEvents
+		}(i, w)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// mergeCalendarEvents joins the events of several windows. An event that
+// shows up in more than one window is kept once, with the union of its slots
+// and bookings. Events are sorted by start, slots and bookings within an
+// event by start too, so that the result does not depend on the windows.
+func mergeCalendarEvents(chunks [][]CalendarEvent) []CalendarEvent {
+	var events []CalendarEvent
+	index := make(map[string]int)
+
+	for _, chunk := range chunks {
+		for _, e := range chunk {
+			i, seen := index[e.ID]
+			if !seen || e.ID == "" {
+				index[e.ID] = len(events)
+				e.EventSlots = append([]CalendarTimeSlot(nil), e.EventSlots...)
+				e.Bookings = append([]CalendarBooking(nil), e.Bookings...)
+				events = append(events, e)
+				continue
+			}
+
+			merged := &events[i]
+			for _, s := range e.EventSlots {
+				if !hasSlot(merged.EventSlots, s.ID) {
+					merged.EventSlots = append(merged.EventSlots, s)
+				}
+			}
+			for _, b := range e.Bookings {
+				if !hasBooking(merged.Bookings, b.ID) {
+					merged.Bookings = append(merged.Bookings, b)
+				}
+			}
+		}
+	}
+
+	for i := range events {
+		slots := events[i].EventSlots
+		sort.SliceStable(slots, func(a, b int) bool {
+			return earlier(slots[a].Start, slots[a].ID, slots[b].Start, slots[b].ID)
+		})
+		bookings := events[i].Bookings
+		sort.SliceStable(bookings, func(a, b int) bool {
+			return earlier(bookings[a].EventSlot.Start, bookings[a].ID, bookings[b].EventSlot.Start, bookings[b].ID)
+		})
+	}
+	sort.SliceStable(events, func(a, b int) bool {
+		return earlier(events[a].Start, events[a].ID, events[b].Start, events[b].ID)
+	})
+	return events
+}
+
+// earlier orders calendar items by start time, then by ID
+func earlier(startA, idA, startB, idB string) bool {
+	ta, _ := time.Parse(time.RFC3339, startA)
+	tb, _ := time.Parse(time.RFC3339, startB)
+	if !ta.Equal(tb) {
+		return ta.Before(tb)
+	}
+	return idA < idB
+}
+
+func hasSlot(slots []CalendarTimeSlot, id string) bool {
+	for _, s := range slots {
+		if s.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func hasBooking(bookings []CalendarBooking, id string) bool {
+	for _, b := range bookings {
+		if b.ID == id {
+			return true
+		}
+	}
+	return false
+}
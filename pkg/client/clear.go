@@ -0,0 +1,75 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// ClearResult is the outcome of ClearReviewSlots
+type ClearResult struct {
+	Removed []ReviewSlot  // free slots that were removed
+	Skipped []ReviewSlot  // free slots only partly inside the range, left alone
+	Failed  []BatchResult // removals that failed, e.g. because the slot was booked meanwhile
+}
+
+// ClearPlan is what ClearReviewSlots is about to do
+type ClearPlan struct {
+	Targets []ReviewSlot // free slots entirely within the range, by start time
+	Skipped []ReviewSlot // free slots only partly inside the range, left alone
+}
+
+// PlanClear lists the free slots ClearReviewSlots would remove from
+// [from, to] without removing anything, so callers can save them first
+func (c *Client) PlanClear(ctx context.Context, from, to time.Time) (*ClearPlan, error) {
+	slots, _, err := c.GetReviewSlots(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	window := Interval{Start: from, End: to}
+	plan := &ClearPlan{}
+	for _, s := range slots {
+		if s.Type != SlotTypeFree {
+			continue
+		}
+		if window.Contains(s.Interval()) {
+			plan.Targets = append(plan.Targets, s)
+		} else if window.Overlaps(s.Interval()) {
+			plan.Skipped = append(plan.Skipped, s)
+		}
+	}
+	sort.Slice(plan.Targets, func(a, b int) bool { return plan.Targets[a].Start.Before(plan.Targets[b].Start) })
+	return plan, nil
+}
+
+// ClearReviewSlots removes every free slot that lies entirely within
+// [from, to]. Booked slots are never touched: every slot is re-checked right
+// before removal, so one booked after the listing fails with ErrSlotBooked.
+func (c *Client) ClearReviewSlots(ctx context.Context, from, to time.Time) (*ClearResult, error) {
+	plan, err := c.PlanClear(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return c.ApplyClear(ctx, plan), nil
+}
+
+// ApplyClear removes the targets of a plan made by PlanClear, re-checking
+// every slot right before removal as ClearReviewSlots does
+func (c *Client) ApplyClear(ctx context.Context, plan *ClearPlan) *ClearResult {
+	targets := plan.Targets
+	result := &ClearResult{Skipped: plan.Skipped}
+	results := runBatch(ctx, len(targets), DefaultBatchConcurrency, func(ctx context.Context, i int) BatchResult {
+		return BatchResult{Err: c.GuardedRemoveReviewSlot(ctx, targets[i])}
+	})
+	for i, r := range results {
+		r.SlotID = targets[i].ID
+		r.Interval = targets[i].Interval()
+		if r.Err != nil {
+			result.Failed = append(result.Failed, r)
+			continue
+		}
+		result.Removed = append(result.Removed, targets[i])
+	}
+	return result
+}
@@ -9,6 +9,7 @@ import (
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 )
 
@@ -49,6 +50,7 @@ type Client struct {
 	baseURL     string
 	authURL     string
 	authConfig  *AuthConfig
+	authMu      sync.Mutex // serializes token and context loading in Do
 	token       *TokenResponse
 	tokenExpiry time.Time
 	// Additional headers required for some API operations
@@ -58,6 +60,16 @@ type Client struct {
 	eduOrgUnitID  string
 	routeInfo     string
 	contextLoaded bool
+	// Client-side checks run before slot mutations
+	slotRules          SlotRules
+	skipSlotValidation bool
+	conflictPolicy     ConflictPolicy
+	conflictHandler    func(*ConflictError)
+	slotFilter         SlotFilter
+	mutationObserver   func(Mutation)
+	// Long calendar ranges are fetched in windows of this size
+	calendarChunk       time.Duration
+	calendarConcurrency int
 }
 
 // ClientOption is a function that configures a Client
@@ -119,15 +131,60 @@ func WithRouteInfo(route string) ClientOption {
 	}
 }
 
+// WithSlotRules sets the constraints slots are validated against
+func WithSlotRules(rules SlotRules) ClientOption {
+	return func(c *Client) {
+		c.slotRules = rules
+	}
+}
+
+// WithoutSlotValidation disables client-side validation in AddReviewSlot
+// and UpdateReviewSlot, leaving every check to the platform
+func WithoutSlotValidation() ClientOption {
+	return func(c *Client) {
+		c.skipSlotValidation = true
+	}
+}
+
+// WithConflictPolicy sets how AddReviewSlot and UpdateReviewSlot treat
+// overlaps with other calendar events
+func WithConflictPolicy(policy ConflictPolicy) ClientOption {
+	return func(c *Client) {
+		c.conflictPolicy = policy
+	}
+}
+
+// WithConflictHandler sets a function that receives the conflicts the
+// conflict policy only warns about
+func WithConflictHandler(handler func(*ConflictError)) ClientOption {
+	return func(c *Client) {
+		c.conflictHandler = handler
+	}
+}
+
+// WithSlotFilter sets a filter that every slot created or moved has to pass,
+// such as blackout times
+func WithSlotFilter(filter SlotFilter) ClientOption {
+	return func(c *Client) {
+		c.slotFilter = filter
+	}
+}
+
 // NewClient creates a new API client
 func NewClient(authConfig *AuthConfig, opts ...ClientOption) *Client {
 	c := &Client{
 		httpClient: &http.Client{
 			Timeout: 30 * time.Second,
 		},
-		baseURL:    DefaultBaseURL,
-		authURL:    DefaultAuthURL,
-		authConfig: authConfig,
+		baseURL:             DefaultBaseURL,
+		authURL:             DefaultAuthURL,
+		authConfig:          authConfig,
+		calendarChunk:       DefaultCalendarChunk,
+		calendarConcurrency: DefaultBatchConcurrency,
+	}
+	c.conflictPolicy = make(ConflictPolicy, len(DefaultConflictPolicy))
+	for kind, action := range DefaultConflictPolicy {
+		c.conflictPolicy[kind] = action
 	}
 
 	for _, opt := range opts {
@@ -258,15 +315,28 @@ type GraphQLError struct {
 	Extensions map[string]interface{} `json:"extensions,omitempty"`
 }
 
-// Do executes a GraphQL request
-func (c *Client) Do(ctx context.Context, req *GraphQLRequest, resp interface{}) error {
+// prepare loads the token and context info if needed and returns the access
+// token to send, so that concurrent requests authenticate only once
+func (c *Client) prepare(ctx context.Context) (string, error) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+
 	if err := c.ensureToken(ctx); err != nil {
-		return fmt.Errorf("ensure token: %w", err)
+		return "", fmt.Errorf("ensure token: %w", err)
 	}
 
 	// Auto-load context info if not already set manually
 	if err := c.ensureContext(ctx); err != nil {
-		return fmt.Errorf("ensure context: %w", err)
+		return "", fmt.Errorf("ensure context: %w", err)
+	}
+	return c.token.AccessToken, nil
+}
+
+// Do executes a GraphQL request. It is safe for concurrent use.
+func (c *Client) Do(ctx context.Context, req *GraphQLRequest, resp interface{}) error {
+	accessToken, err := c.prepare(ctx)
+	if err != nil {
+		return err
 	}
 
 	body, err := json.Marshal(req)
@@ -281,7 +351,7 @@ func (c *Client) Do(ctx context.Context, req *GraphQLRequest, resp interface{})
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.token.AccessToken)
+	httpReq.Header.Set("Authorization", "Bearer "+accessToken)
 
 	// Add additional headers if set
 	if c.schoolID != "" {
@@ -356,6 +426,36 @@ func (c *Client) GetToken() *TokenResponse {
 	return c.token
 }
 
+// SetSlotValidation turns client-side slot validation on or off
+func (c *Client) SetSlotValidation(enabled bool) {
+	c.skipSlotValidation = !enabled
+}
+
+// SetSlotRules changes the constraints slots are validated against
+func (c *Client) SetSlotRules(rules SlotRules) {
+	c.slotRules = rules
+}
+
+// SetConflictAction changes what an overlap with one kind of event does
+func (c *Client) SetConflictAction(kind EventKind, action ConflictAction) {
+	if c.conflictPolicy == nil {
+		c.conflictPolicy = make(ConflictPolicy)
+	}
+	c.conflictPolicy[kind] = action
+}
+
+// SetConflictHandler sets a function that receives the conflicts the
+// conflict policy only warns about
+func (c *Client) SetConflictHandler(handler func(*ConflictError)) {
+	c.conflictHandler = handler
+}
+
+// SetSlotFilter sets the filter every slot created or moved has to pass, nil
+// for none
+func (c *Client) SetSlotFilter(filter SlotFilter) {
+	c.slotFilter = filter
+}
+
 // SetBaseURL sets the base URL (useful for testing)
 func (c *Client) SetBaseURL(url string) {
 	c.baseURL = url
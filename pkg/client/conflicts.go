@@ -0,0 +1,88 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictAction is what happens when a slot overlaps a calendar event
+type ConflictAction string
+
+const (
+	// ConflictBlock refuses to create or move the slot
+	ConflictBlock ConflictAction = "block"
+	// ConflictWarn creates or moves the slot and reports the conflict to
+	// the client's conflict handler
+	ConflictWarn ConflictAction = "warn"
+	// ConflictIgnore does not check the event kind at all
+	ConflictIgnore ConflictAction = "ignore"
+)
+
+// ConflictPolicy maps event kinds to what an overlap with them does. Kinds
+// that are not in the map are ignored.
+type ConflictPolicy map[EventKind]ConflictAction
+
+// DefaultConflictPolicy blocks slots over exams, registered activities,
+// penalties and reviews of our own projects, and warns about other events.
+// Overlaps with our own slots and the reviews we conduct are left to
+// ValidateSlot.
+var DefaultConflictPolicy = ConflictPolicy{
+	EventExam:     ConflictBlock,
+	EventActivity: ConflictBlock,
+	EventPenalty:  ConflictBlock,
+	EventReviewed: ConflictBlock,
+	EventOther:    ConflictWarn,
+}
+
+// ParseConflictAction parses block, warn or ignore
+func ParseConflictAction(s string) (ConflictAction, error) {
+	switch a := ConflictAction(strings.ToLower(s)); a {
+	case ConflictBlock, ConflictWarn, ConflictIgnore:
+		return a, nil
+	}
+	return "", fmt.Errorf("invalid conflict action %q (use block, warn or ignore)", s)
+}
+
+// enabled reports whether any event kind is checked
+func (p ConflictPolicy) enabled() bool {
+	for _, a := range p {
+		if a == ConflictBlock || a == ConflictWarn {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns the events a slot overlaps, split by whether the policy
+// blocks or only warns about them
+func (p ConflictPolicy) Check(slot Interval, busy []BusyEvent) (blocked, warned []BusyEvent) {
+	for _, e := range busy {
+		if !e.Overlaps(slot) {
+			continue
+		}
+		switch p[e.Kind] {
+		case ConflictBlock:
+			blocked = append(blocked, e)
+		case ConflictWarn:
+			warned = append(warned, e)
+		}
+	}
+	return blocked, warned
+}
+
+// ConflictError lists the calendar events a slot overlaps. It is returned
+// for blocking conflicts and passed to the conflict handler for warnings.
+type ConflictError struct {
+	Slot      Interval
+	Conflicts []BusyEvent
+}
+
+func (e *ConflictError) Error() string {
+	msgs := make([]string, len(e.Conflicts))
+	for i, c := range e.Conflicts {
+		msgs[i] = c.String()
+	}
+	return fmt.Sprintf("slot %s - %s conflicts with %s",
+		e.Slot.Start.Format("2006-01-02 15:04"), e.Slot.End.Format("2006-01-02 15:04"),
+		strings.Join(msgs, ", "))
+}
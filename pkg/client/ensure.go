@@ -0,0 +1,108 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"time"
+)
+
+// EnsureResult is the outcome of EnsureReviewSlot
+type EnsureResult struct {
+	Requested Interval
+	Slots     []ReviewSlot // slots that cover the requested interval, by start time
+	Added     []ReviewSlot // slots created for uncovered parts
+	Extended  []ReviewSlot // free slots stretched over an adjacent uncovered part, with their new times
+}
+
+// Changed reports whether the calendar had to be changed
+func (r *EnsureResult) Changed() bool {
+	return len(r.Added) > 0 || len(r.Extended) > 0
+}
+
+// EnsureReviewSlot makes sure the interval is covered by review slots, so
+// that running it again for the same interval changes nothing. Parts
+// already covered by a slot are left alone. An uncovered part next to a free
+// slot extends that slot when the slot rules allow it, so repeated runs do
+// not fragment the calendar; any other uncovered part becomes a new slot.
+// Parts the slot filter clips away are not covered.
+// On error the result reports what was done before it.
+func (c *Client) EnsureReviewSlot(ctx context.Context, start, end time.Time) (*EnsureResult, error) {
+	want := Interval{Start: start, End: end}
+	res := &EnsureResult{Requested: want}
+
+	// Only the parts the slot filter allows are to be covered
+	allowed, err := c.filterSlot(want, true)
+	if err != nil {
+		return res, err
+	}
+
+	// Look a little past the interval to find the free slots next to it
+	slots, _, err := c.GetReviewSlots(ctx, start.Add(-time.Minute), end.Add(time.Minute))
+	if err != nil {
+		return res, err
+	}
+
+	var covered []Interval
+	for _, s := range slots {
+		covered = append(covered, s.Interval())
+	}
+
+	for _, gap := range SubtractIntervals(allowed, covered) {
+		if i := adjacentFreeSlot(slots, gap); i >= 0 {
+			span := Interval{Start: slots[i].Start, End: gap.End}
+			if slots[i].Start.Equal(gap.End) {
+				span = Interval{Start: gap.Start, End: slots[i].End}
+			}
+			extended, err := c.GuardedUpdateReviewSlot(ctx, slots[i], span.Start, span.End)
+			if err == nil {
+				slots[i] = *extended
+				res.Extended = append(res.Extended, *extended)
+				continue
+			}
+			var vErr *ValidationError
+			var cErr *ConflictError
+			if !errors.As(err, &vErr) && !errors.As(err, &cErr) && !errors.Is(err, ErrSlotFiltered) {
+				res.Slots = coveringSlots(slots, want)
+				return res, err
+			}
+			// The stretched slot breaks a rule, such as the maximum
+			// duration, or the slot itself lies in a blackout: add the
+			// gap as a slot of its own instead
+		}
+
+		added, err := c.AddReviewSlot(ctx, gap.Start, gap.End)
+		res.Added = append(res.Added, added...)
+		slots = append(slots, added...)
+		if err != nil {
+			res.Slots = coveringSlots(slots, want)
+			return res, err
+		}
+	}
+
+	res.Slots = coveringSlots(slots, want)
+	return res, nil
+}
+
+// adjacentFreeSlot returns the index of a free slot that ends where gap
+// starts or starts where it ends, or -1
+func adjacentFreeSlot(slots []ReviewSlot, gap Interval) int {
+	for i, s := range slots {
+		if s.Type == SlotTypeFree && (s.End.Equal(gap.Start) || s.Start.Equal(gap.End)) {
+			return i
+		}
+	}
+	return -1
+}
+
+// coveringSlots returns the slots that overlap want, by start time
+func coveringSlots(slots []ReviewSlot, want Interval) []ReviewSlot {
+	var result []ReviewSlot
+	for _, s := range slots {
+		if s.Interval().Overlaps(want) {
+			result = append(result, s)
+		}
+	}
+	sort.Slice(result, func(a, b int) bool { return result[a].Start.Before(result[b].Start) })
+	return result
+}
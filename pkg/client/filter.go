@@ -0,0 +1,32 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrSlotFiltered is returned when the client's slot filter refuses a slot
+var ErrSlotFiltered = errors.New("slot refused by the slot filter")
+
+// SlotFilter decides which parts of a requested slot may be offered. It
+// returns the allowed parts, or an error refusing the slot. With clip false
+// the slot cannot be split, as when an existing slot is moved, and the
+// filter must either allow it unchanged or refuse it.
+type SlotFilter func(slot Interval, clip bool) ([]Interval, error)
+
+// filterSlot runs the client's slot filter, if any. Refusals wrap
+// ErrSlotFiltered as well as the filter's own error.
+func (c *Client) filterSlot(slot Interval, clip bool) ([]Interval, error) {
+	if c.slotFilter == nil {
+		return []Interval{slot}, nil
+	}
+	parts, err := c.slotFilter(slot, clip)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrSlotFiltered, err)
+	}
+	if !clip && (len(parts) != 1 || !parts[0].Equal(slot)) {
+		return nil, fmt.Errorf("%w: slot %s - %s cannot be moved there in one piece", ErrSlotFiltered,
+			slot.Start.Format("2006-01-02 15:04"), slot.End.Format("15:04"))
+	}
+	return parts, nil
+}
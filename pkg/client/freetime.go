@@ -0,0 +1,110 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// FreeTimeConstraints shape the gaps FindFreeTime returns. A zero value
+// returns every gap in the calendar as is.
+type FreeTimeConstraints struct {
+	MinLength   time.Duration  // shortest gap worth returning
+	MaxLength   time.Duration  // longer gaps are cut into pieces of this length
+	Buffer      time.Duration  // time kept free before and after every busy event
+	Granularity time.Duration  // gap starts are rounded up and ends down to this
+	DayStart    time.Duration  // start of working hours as an offset from midnight
+	DayEnd      time.Duration  // end of working hours, no bound if zero
+	Location    *time.Location // zone working hours are in, UTC if nil
+	Ignore      []EventKind    // kinds of events that do not count as busy
+}
+
+// workingHours returns the parts of window within the daily working hours
+func (c FreeTimeConstraints) workingHours(window Interval) []Interval {
+	if c.DayEnd <= c.DayStart {
+		return []Interval{window}
+	}
+	loc := c.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+
+	var result []Interval
+	t := window.Start.In(loc)
+	for day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc); day.Before(window.End); day = day.AddDate(0, 0, 1) {
+		hours := Interval{Start: day.Add(c.DayStart), End: day.Add(c.DayEnd)}
+		if iv, ok := hours.Intersect(window); ok {
+			result = append(result, iv)
+		}
+	}
+	return result
+}
+
+// FindFree returns the gaps in window that no busy event, widened by the
+// buffer, touches, shaped by the constraints
+func FindFree(window Interval, busy []BusyEvent, c FreeTimeConstraints) []Interval {
+	ignored := make(map[EventKind]bool)
+	for _, k := range c.Ignore {
+		ignored[k] = true
+	}
+
+	var taken []Interval
+	for _, e := range busy {
+		if ignored[e.Kind] {
+			continue
+		}
+		taken = append(taken, Interval{Start: e.Start.Add(-c.Buffer), End: e.End.Add(c.Buffer)})
+	}
+
+	var result []Interval
+	for _, gap := range SubtractIntervals(c.workingHours(window), taken) {
+		if c.Granularity > 0 {
+			if start := gap.Start.Truncate(c.Granularity); start.Before(gap.Start) {
+				gap.Start = start.Add(c.Granularity)
+			}
+			gap.End = gap.End.Truncate(c.Granularity)
+		}
+		if gap.IsEmpty() || gap.Duration() < c.MinLength {
+			continue
+		}
+		if c.MaxLength <= 0 {
+			result = append(result, gap)
+			continue
+		}
+		for _, piece := range SplitInterval(gap, c.MaxLength) {
+			if piece.Duration() >= c.MinLength {
+				result = append(result, piece)
+			}
+		}
+	}
+	return result
+}
+
+// FindFreeTime returns the gaps in the whole calendar between from and to,
+// taking exams, activities, penalties, reviews and our own slots into
+// account. The range is narrowed to where the client's slot rules allow new
+// slots, and unset MaxLength and Granularity default to those rules.
+func (c *Client) FindFreeTime(ctx context.Context, from, to time.Time, constraints FreeTimeConstraints) ([]Interval, error) {
+	now := time.Now()
+	if c.slotRules.MinLeadTime > 0 && from.Before(now.Add(c.slotRules.MinLeadTime)) {
+		from = now.Add(c.slotRules.MinLeadTime)
+	}
+	if c.slotRules.MaxHorizon > 0 && to.After(now.Add(c.slotRules.MaxHorizon)) {
+		to = now.Add(c.slotRules.MaxHorizon)
+	}
+	if !to.After(from) {
+		return nil, nil
+	}
+	if constraints.MaxLength == 0 {
+		constraints.MaxLength = c.slotRules.MaxDuration
+	}
+	if constraints.Granularity == 0 {
+		constraints.Granularity = c.slotRules.Granularity
+	}
+
+	// Events just outside the range still matter because of the buffer
+	busy, err := c.GetBusyEvents(ctx, from.Add(-constraints.Buffer), to.Add(constraints.Buffer))
+	if err != nil {
+		return nil, err
+	}
+	return FindFree(Interval{Start: from, End: to}, busy, constraints), nil
+}
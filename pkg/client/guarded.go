@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrSlotBooked is returned by guarded mutations when the slot was
+	// booked after it was read
+	ErrSlotBooked = errors.New("slot is booked")
+	// ErrSlotNotFound is returned by guarded mutations when the slot is no
+	// longer where it was read
+	ErrSlotNotFound = errors.New("slot not found")
+)
+
+// CheckSlotFree re-fetches a slot right before it is mutated. It returns an
+// error wrapping ErrSlotBooked if the slot turned into a booking, and one
+// wrapping ErrSlotNotFound if it was removed or moved in the meantime. Only
+// the ID and times of slot are used.
+func (c *Client) CheckSlotFree(ctx context.Context, slot ReviewSlot) error {
+	slots, bookings, err := c.GetReviewSlots(ctx, slot.Start, slot.End)
+	if err != nil {
+		return fmt.Errorf("re-check slot %s: %w", slot.ID, err)
+	}
+
+	for _, b := range bookings {
+		if b.SlotID == slot.ID {
+			return fmt.Errorf("%w: slot %s holds a review of %s", ErrSlotBooked, slot.ID, b.ProjectName)
+		}
+	}
+	for _, s := range slots {
+		if s.ID != slot.ID {
+			continue
+		}
+		if s.Type != SlotTypeFree {
+			return fmt.Errorf("%w: slot %s is %s", ErrSlotBooked, slot.ID, s.Type)
+		}
+		return nil
+	}
+	return fmt.Errorf("%w: slot %s", ErrSlotNotFound, slot.ID)
+}
+
+// GuardedRemoveReviewSlot removes a slot only if it is still free
+func (c *Client) GuardedRemoveReviewSlot(ctx context.Context, slot ReviewSlot) error {
+	if err := c.CheckSlotFree(ctx, slot); err != nil {
+		return err
+	}
+	return c.removeReviewSlot(ctx, slot.ID, &slot)
+}
+
+// GuardedUpdateReviewSlot changes the time of a slot only if it is still free
+func (c *Client) GuardedUpdateReviewSlot(ctx context.Context, slot ReviewSlot, newStart, newEnd time.Time) (*ReviewSlot, error) {
+	if err := c.CheckSlotFree(ctx, slot); err != nil {
+		return nil, err
+	}
+	return c.updateReviewSlot(ctx, slot.ID, newStart, newEnd, &slot)
+}
@@ -0,0 +1,123 @@
+package client
+
+import (
+	"sort"
+	"time"
+)
+
+// Interval is a half-open time range [Start, End)
+type Interval struct {
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
+
+// Duration returns the length of the interval
+func (i Interval) Duration() time.Duration {
+	return i.End.Sub(i.Start)
+}
+
+// IsEmpty reports whether the interval has no length
+func (i Interval) IsEmpty() bool {
+	return !i.End.After(i.Start)
+}
+
+// Equal reports whether both intervals start and end at the same instants
+func (i Interval) Equal(o Interval) bool {
+	return i.Start.Equal(o.Start) && i.End.Equal(o.End)
+}
+
+// Overlaps reports whether the intervals share any time
+func (i Interval) Overlaps(o Interval) bool {
+	return i.Start.Before(o.End) && o.Start.Before(i.End)
+}
+
+// Contains reports whether o lies entirely within i
+func (i Interval) Contains(o Interval) bool {
+	return !o.Start.Before(i.Start) && !o.End.After(i.End)
+}
+
+// Intersect returns the common part of both intervals
+func (i Interval) Intersect(o Interval) (Interval, bool) {
+	start := i.Start
+	if o.Start.After(start) {
+		start = o.Start
+	}
+	end := i.End
+	if o.End.Before(end) {
+		end = o.End
+	}
+	r := Interval{Start: start, End: end}
+	return r, !r.IsEmpty()
+}
+
+// Interval returns the time range covered by the slot
+func (s ReviewSlot) Interval() Interval {
+	return Interval{Start: s.Start, End: s.End}
+}
+
+// Interval returns the time range covered by the booking
+func (b ReviewBooking) Interval() Interval {
+	return Interval{Start: b.Start, End: b.End}
+}
+
+// SortIntervals sorts intervals by start, then by end
+func SortIntervals(in []Interval) {
+	sort.Slice(in, func(a, b int) bool {
+		if in[a].Start.Equal(in[b].Start) {
+			return in[a].End.Before(in[b].End)
+		}
+		return in[a].Start.Before(in[b].Start)
+	})
+}
+
+// MergeIntervals returns a sorted copy of in with overlapping and adjacent
+// intervals joined together. Empty intervals are dropped.
+func MergeIntervals(in []Interval) []Interval {
+	sorted := make([]Interval, 0, len(in))
+	for _, i := range in {
+		if !i.IsEmpty() {
+			sorted = append(sorted, i)
+		}
+	}
+	SortIntervals(sorted)
+
+	var merged []Interval
+	for _, i := range sorted {
+		n := len(merged)
+		if n > 0 && !i.Start.After(merged[n-1].End) {
+			if i.End.After(merged[n-1].End) {
+				merged[n-1].End = i.End
+			}
+			continue
+		}
+		merged = append(merged, i)
+	}
+	return merged
+}
+
+// SubtractIntervals returns the parts of from that are not covered by any
+// interval in remove. The result is sorted and merged.
+func SubtractIntervals(from, remove []Interval) []Interval {
+	remove = MergeIntervals(remove)
+
+	var result []Interval
+	for _, i := range MergeIntervals(from) {
+		cur := i
+		for _, r := range remove {
+			if !r.Overlaps(cur) {
+				continue
+			}
+			if r.Start.After(cur.Start) {
+				result = append(result, Interval{Start: cur.Start, End: r.Start})
+			}
+			cur.Start = r.End
+			if cur.IsEmpty() {
+				break
+			}
+		}
+		if !cur.IsEmpty() {
+			result = append(result, cur)
+		}
+	}
+	return result
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"time"
+)
+
+// MutationOp is the kind of a slot mutation
+type MutationOp string
+
+const (
+	MutationAdd    MutationOp = "add"
+	MutationUpdate MutationOp = "update"
+	MutationRemove MutationOp = "remove"
+)
+
+// Mutation describes a slot mutation the client sent, for observers that
+// keep a record of them. Before is the slot as it was, when known; After
+// holds the slots the platform returned.
+type Mutation struct {
+	Op        MutationOp   `json:"op"`
+	SlotID    string       `json:"slotId,omitempty"`
+	Requested *Interval    `json:"requested,omitempty"` // times asked for by add and update
+	Before    *ReviewSlot  `json:"before,omitempty"`
+	After     []ReviewSlot `json:"after,omitempty"`
+	Error     string       `json:"error,omitempty"`
+	At        time.Time    `json:"at"`
+}
+
+// Failed reports whether the platform rejected the mutation
+func (m Mutation) Failed() bool {
+	return m.Error != ""
+}
+
+// WithMutationObserver sets a function that is called after every slot
+// mutation sent to the platform. It may be called from several goroutines
+// at once by the batch operations.
+func WithMutationObserver(observer func(Mutation)) ClientOption {
+	return func(c *Client) {
+		c.mutationObserver = observer
+	}
+}
+
+// SetMutationObserver sets a function that is called after every slot
+// mutation sent to the platform
+func (c *Client) SetMutationObserver(observer func(Mutation)) {
+	c.mutationObserver = observer
+}
+
+// observe reports a mutation to the observer, if any. Mutations rejected by
+// the client-side checks never reached the platform and are not reported.
+func (c *Client) observe(m Mutation, err error) {
+	if c.mutationObserver == nil {
+		return
+	}
+	if err != nil {
+		m.Error = err.Error()
+	}
+	m.At = time.Now()
+	c.mutationObserver(m)
+}
+
+// preImage looks up the slot a mutation is about to change, so observers get
+// its previous state. It is only fetched when there is an observer, and a
+// failed lookup leaves the state unknown rather than failing the mutation.
+func (c *Client) preImage(ctx context.Context, slotID string, known *ReviewSlot) *ReviewSlot {
+	if c.mutationObserver == nil || known != nil {
+		return known
+	}
+	slot, err := c.lookupSlot(ctx, slotID)
+	if err != nil {
+		return nil
+	}
+	return slot
+}
+
+// lookupSlot finds a slot by ID anywhere a slot can currently be
+func (c *Client) lookupSlot(ctx context.Context, slotID string) (*ReviewSlot, error) {
+	from, to := c.slotRange(time.Now())
+	return c.FindReviewSlot(ctx, slotID, from, to)
+}
+
+// slotRange is where a slot can currently be: from a day ago up to the slot
+// horizon
+func (c *Client) slotRange(now time.Time) (time.Time, time.Time) {
+	horizon := c.slotRules.MaxHorizon
+	if horizon <= 0 {
+		horizon = DefaultSlotRules.MaxHorizon
+	}
+	return now.Add(-24 * time.Hour), now.Add(horizon + 24*time.Hour)
+}
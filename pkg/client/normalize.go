@@ -0,0 +1,218 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MergeResult is the outcome of merging one group of free slots
+type MergeResult struct {
+	Merged []ReviewSlot // the original slots, in start order
+	Slot   *ReviewSlot  // the resulting slot, nil if the merge failed
+	Err    error
+}
+
+// MergeGroups finds free slots that overlap or touch each other and returns
+// them in groups of two or more, each sorted by start time. Booked slots are
+// ignored and never end up in a group.
+func MergeGroups(slots []ReviewSlot) [][]ReviewSlot {
+	var free []ReviewSlot
+	for _, s := range slots {
+		if s.Type == SlotTypeFree {
+			free = append(free, s)
+		}
+	}
+	sort.Slice(free, func(a, b int) bool { return free[a].Start.Before(free[b].Start) })
+
+	var groups [][]ReviewSlot
+	var cur []ReviewSlot
+	var curEnd time.Time
+	for _, s := range free {
+		if len(cur) > 0 && !s.Start.After(curEnd) {
+			cur = append(cur, s)
+			if s.End.After(curEnd) {
+				curEnd = s.End
+			}
+			continue
+		}
+		if len(cur) > 1 {
+			groups = append(groups, cur)
+		}
+		cur = []ReviewSlot{s}
+		curEnd = s.End
+	}
+	if len(cur) > 1 {
+		groups = append(groups, cur)
+	}
+	return groups
+}
+
+// SplitInterval cuts an interval into consecutive pieces of the given
+// length. The last piece holds the remainder and may be shorter.
+func SplitInterval(iv Interval, every time.Duration) []Interval {
+	if every <= 0 || iv.IsEmpty() {
+		return []Interval{iv}
+	}
+	var pieces []Interval
+	for start := iv.Start; start.Before(iv.End); start = start.Add(every) {
+		end := start.Add(every)
+		if end.After(iv.End) {
+			end = iv.End
+		}
+		pieces = append(pieces, Interval{Start: start, End: end})
+	}
+	return pieces
+}
+
+// MergeSlots joins a group of overlapping or adjacent free slots into the
+// first one. The merged span is checked against the slot rules and the
+// conflict policy before anything changes. The other slots are then removed
+// before the first is extended, so that the extended slot never overlaps
+// them; if that still fails, the removed slots are added back. Every slot is
+// re-checked right before it is touched and the merge stops with
+// ErrSlotBooked if one was booked in the meantime.
+func (c *Client) MergeSlots(ctx context.Context, group []ReviewSlot) (*ReviewSlot, error) {
+	if len(group) < 2 {
+		return nil, fmt.Errorf("need at least two slots to merge")
+	}
+
+	span := group[0].Interval()
+	ids := make([]string, len(group))
+	for i, s := range group {
+		if s.Type != SlotTypeFree {
+			return nil, fmt.Errorf("slot %s is %s, only free slots can be merged", s.ID, s.Type)
+		}
+		ids[i] = s.ID
+		if s.Start.Before(span.Start) {
+			span.Start = s.Start
+		}
+		if s.End.After(span.End) {
+			span.End = s.End
+		}
+	}
+	if err := c.checkSlot(ctx, span, ids...); err != nil {
+		return nil, fmt.Errorf("merged slot: %w", err)
+	}
+
+	var removed []ReviewSlot
+	for _, s := range group[1:] {
+		if err := c.GuardedRemoveReviewSlot(ctx, s); err != nil {
+			return nil, c.restoreMerged(ctx, removed, fmt.Errorf("remove slot %s: %w", s.ID, err))
+		}
+		removed = append(removed, s)
+	}
+
+	slot, err := c.GuardedUpdateReviewSlot(ctx, group[0], span.Start, span.End)
+	if err != nil {
+		return nil, c.restoreMerged(ctx, removed, fmt.Errorf("extend slot %s: %w", group[0].ID, err))
+	}
+	return slot, nil
+}
+
+// restoreMerged adds back the slots a failed merge removed and returns the
+// merge error together with any slot that could not be restored
+func (c *Client) restoreMerged(ctx context.Context, removed []ReviewSlot, cause error) error {
+	errs := []error{cause}
+	for _, s := range removed {
+		if _, err := c.AddReviewSlot(ctx, s.Start, s.End); err != nil {
+			errs = append(errs, fmt.Errorf("restore slot %s (%s - %s): %w", s.ID,
+				s.Start.Format("2006-01-02 15:04"), s.End.Format("15:04"), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// NormalizeReviewSlots merges every group of overlapping or adjacent free
+// slots in the range into a single slot. Booked slots stay untouched. A
+// failed group does not stop the others; its error is in its MergeResult.
+func (c *Client) NormalizeReviewSlots(ctx context.Context, from, to time.Time) ([]MergeResult, error) {
+	slots, _, err := c.GetReviewSlots(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var results []MergeResult
+	for _, group := range MergeGroups(slots) {
+		slot, err := c.MergeSlots(ctx, group)
+		results = append(results, MergeResult{Merged: group, Slot: slot, Err: err})
+	}
+	return results, nil
+}
+
+// SplitReviewSlot breaks a free slot into consecutive slots of the given
+// length. Every piece is checked against the slot rules, the conflict policy
+// and the slot filter before anything changes. The original slot is then
+// shrunk to the first piece and the remaining pieces are added as new slots;
+// if an add still fails, the added pieces are removed and the original slot
+// is grown back. It returns every resulting slot. It fails with
+// ErrSlotBooked if the slot was booked after it was read.
+func (c *Client) SplitReviewSlot(ctx context.Context, slot ReviewSlot, every time.Duration) ([]ReviewSlot, error) {
+	if slot.Type != SlotTypeFree {
+		return nil, fmt.Errorf("slot %s is %s, only free slots can be split", slot.ID, slot.Type)
+	}
+
+	pieces := SplitInterval(slot.Interval(), every)
+	if len(pieces) < 2 {
+		return nil, fmt.Errorf("slot %s is not longer than %s", slot.ID, every)
+	}
+	for _, p := range pieces {
+		err := c.checkSlot(ctx, p, slot.ID)
+		if err == nil {
+			_, err = c.filterSlot(p, false)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("piece %s - %s: %w", p.Start.Format("2006-01-02 15:04"), p.End.Format("15:04"), err)
+		}
+	}
+
+	first, err := c.GuardedUpdateReviewSlot(ctx, slot, pieces[0].Start, pieces[0].End)
+	if err != nil {
+		return nil, fmt.Errorf("shrink slot %s: %w", slot.ID, err)
+	}
+
+	result := []ReviewSlot{*first}
+	for _, p := range pieces[1:] {
+		added, err := c.AddReviewSlot(ctx, p.Start, p.End)
+		result = append(result, added...)
+		if err != nil {
+			return nil, c.restoreSplit(ctx, slot, result, fmt.Errorf("add piece %s - %s: %w",
+				p.Start.Format("2006-01-02 15:04"), p.End.Format("15:04"), err))
+		}
+	}
+	return result, nil
+}
+
+// restoreSplit removes the pieces a failed split added, grows the shrunk
+// first piece back to the original slot and returns the split error together
+// with anything that could not be undone
+func (c *Client) restoreSplit(ctx context.Context, orig ReviewSlot, pieces []ReviewSlot, cause error) error {
+	errs := []error{cause}
+	for _, s := range pieces[1:] {
+		if err := c.GuardedRemoveReviewSlot(ctx, s); err != nil {
+			errs = append(errs, fmt.Errorf("remove piece %s: %w", s.ID, err))
+		}
+	}
+	if _, err := c.GuardedUpdateReviewSlot(ctx, pieces[0], orig.Start, orig.End); err != nil {
+		errs = append(errs, fmt.Errorf("restore slot %s (%s - %s): %w", orig.ID,
+			orig.Start.Format("2006-01-02 15:04"), orig.End.Format("15:04"), err))
+	}
+	return errors.Join(errs...)
+}
+
+// FindReviewSlot looks up a slot by ID among the slots in the range
+func (c *Client) FindReviewSlot(ctx context.Context, slotID string, from, to time.Time) (*ReviewSlot, error) {
+	slots, _, err := c.GetReviewSlots(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range slots {
+		if s.ID == slotID {
+			return &s, nil
+		}
+	}
+	return nil, fmt.Errorf("slot %s not found between %s and %s", slotID,
+		from.Format("2006-01-02"), to.Format("2006-01-02"))
+}
@@ -1,6 +1,9 @@
 package client
 
-import "context"
+import (
+	"context"
+	"time"
+)
 
 // GraphQL Queries - exact copies from the API specification
 
@@ -353,6 +356,7 @@ fragment CalendarReviewBooking on CalendarBooking {
   eventSlotId
   task {
     id
+    title
     goalId
     goalName
     studentTaskAdditionalAttributes {
@@ -392,6 +396,10 @@ fragment CalendarReviewBooking on CalendarBooking {
     }
     __typename
   }
+  team {
+    ...ProjectTeamMembers
+    __typename
+  }
   bookingStatus
   isOnline
   vcLinkUrl
@@ -424,6 +432,48 @@ fragment VerifiableStudentItem on VerifiableStudent {
   __typename
 }
 
+fragment ProjectTeamMembers on ProjectTeamMembers {
+  id
+  teamLead {
+    ...ProjectTeamMember
+    __typename
+  }
+  members {
+    ...ProjectTeamMember
+    __typename
+  }
+  invitedUsers {
+    ...ProjectTeamMember
+    __typename
+  }
+  teamName
+  teamStatus
+  minTeamMemberCount
+  maxTeamMemberCount
+  __typename
+}
+
+fragment ProjectTeamMember on User {
+  id
+  avatarUrl
+  login
+  userExperience {
+    level {
+      id
+      range {
+        levelCode
+        __typename
+      }
+      __typename
+    }
+    cookiesCount
+    codeReviewPoints
+    __typename
+  }
+  activeSchoolShortName
+  __typename
+}
+
 fragment CalendarEventExam on Exam {
   examId
   eventId
@@ -1040,8 +1090,29 @@ func (c *Client) GetMyReviews(ctx context.Context, to string, limit int) (*GetMy
 	return &resp, nil
 }
 
-// GetCalendarEvents fetches calendar events
+// GetCalendarEvents fetches calendar events. Ranges longer than the
+// client's calendar chunk are fetched as several windows in parallel and
+// merged, with events, slots and bookings deduplicated by ID.
 func (c *Client) GetCalendarEvents(ctx context.Context, from, to string) (*GetMyCalendarEventsData, error) {
+	start, errFrom := time.Parse(time.RFC3339, from)
+	end, errTo := time.Parse(time.RFC3339, to)
+	if errFrom != nil || errTo != nil {
+		// Leave ranges we cannot split to the server
+		return c.fetchCalendarEvents(ctx, from, to)
+	}
+
+	chunks, err := c.fetchCalendarChunks(ctx, calendarWindows(start, end, c.calendarChunk), c.calendarConcurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp GetMyCalendarEventsData
+	resp.CalendarEventS21.GetMyCalendarEvents = mergeCalendarEvents(chunks)
+	return &resp, nil
+}
+
+// fetchCalendarEvents fetches calendar events with a single request
+func (c *Client) fetchCalendarEvents(ctx context.Context, from, to string) (*GetMyCalendarEventsData, error) {
 	req := &GraphQLRequest{
 		OperationName: "calendarGetEvents",
 		Query:         QueryCalendarGetEvents,
@@ -3,93 +3,217 @@ package client
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 )
 
+const (
+	// SlotTypeFree marks a slot that peers can still book
+	SlotTypeFree = "FREE_TIME"
+	// SlotTypeBooked marks a slot that already holds a review
+	SlotTypeBooked = "BOOKED_TIME"
+)
+
+// Role tells which side of a review we are on
+type Role string
+
+const (
+	// RoleVerifier marks slots and bookings where we conduct the review
+	RoleVerifier Role = "verifier"
+	// RoleVerifiable marks slots and bookings where our project is reviewed
+	RoleVerifiable Role = "verifiable"
+)
+
 // ReviewSlot represents a review slot from the calendar
 type ReviewSlot struct {
-	ID        string    `json:"id"`
-	Start     time.Time `json:"start"`
-	End       time.Time `json:"end"`
-	Type      string    `json:"type"` // FREE_TIME or BOOKED_TIME
-	IsOnline  bool      `json:"isOnline"`
-	VCLink    *string   `json:"vcLink"`
-	School    string    `json:"school"`
+	ID       string    `json:"id"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+	Type     string    `json:"type"`     // FREE_TIME or BOOKED_TIME
+	IsOnline bool      `json:"isOnline"` // taken from the booking, false for free slots
+	VCLink   *string   `json:"vcLink"`   // video call link of the booking, if any
+	Role     Role      `json:"role"`
+	School   string    `json:"school"`
 }
 
 // ReviewBooking represents a booked review
 type ReviewBooking struct {
-	ID            string        `json:"id"`
-	SlotID        string        `json:"slotId"`
-	Start         time.Time     `json:"start"`
-	End           time.Time     `json:"end"`
-	ProjectName   string        `json:"projectName"`
-	VerifierLogin string        `json:"verifierLogin"`
-	IsOnline      bool          `json:"isOnline"`
-	Status        string        `json:"status"`
+	ID            string    `json:"id"`
+	SlotID        string    `json:"slotId"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	ProjectName   string    `json:"projectName"`
+	VerifierLogin string    `json:"verifierLogin"`
+	IsOnline      bool      `json:"isOnline"`
+	Status        string    `json:"status"`
+	Role          Role      `json:"role"`
+	AnswerID      string    `json:"answerId"`
+	VCLink        *string   `json:"vcLink"`
+	// Task under review
+	TaskID         string `json:"taskId"`
+	TaskTitle      string `json:"taskTitle"`
+	GoalID         string `json:"goalId"`
+	AssignmentType string `json:"assignmentType"` // e.g. INDIVIDUAL or GROUP
+	Cookies        int    `json:"cookies"`        // cookies the task awards
+	// People whose work is reviewed
+	TeamName  string        `json:"teamName,omitempty"`
+	Reviewees []Participant `json:"reviewees,omitempty"`
+	Team      []Participant `json:"team,omitempty"` // team lead first, for group projects
+}
+
+// Participant is a student taking part in a review
+type Participant struct {
+	UserID           string `json:"userId"`
+	Login            string `json:"login"`
+	Level            int    `json:"level"`
+	Campus           string `json:"campus"`
+	IsTeamLead       bool   `json:"isTeamLead"`
+	Cookies          int    `json:"cookies"`
+	CodeReviewPoints int    `json:"codeReviewPoints"`
 }
 
 // GetReviewSlots fetches available and booked review slots within a date range
 func (c *Client) GetReviewSlots(ctx context.Context, from, to time.Time) ([]ReviewSlot, []ReviewBooking, error) {
-	fromStr := from.Format("2006-01-02T15:04:05.000Z")
-	toStr := to.Format("2006-01-02T15:04:05.000Z")
+	fromStr := from.UTC().Format("2006-01-02T15:04:05.000Z")
+	toStr := to.UTC().Format("2006-01-02T15:04:05.000Z")
 
 	resp, err := c.GetCalendarEvents(ctx, fromStr, toStr)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	slots, bookings := reviewSlotsFromEvents(resp.CalendarEventS21.GetMyCalendarEvents)
+	return slots, bookings, nil
+}
+
+// reviewSlotsFromEvents extracts review slots and bookings from calendar events
+func reviewSlotsFromEvents(events []CalendarEvent) ([]ReviewSlot, []ReviewBooking) {
 	var slots []ReviewSlot
 	var bookings []ReviewBooking
 
-	for _, event := range resp.CalendarEventS21.GetMyCalendarEvents {
+	for _, event := range events {
 		// Filter for student_check events (review slots)
 		if event.EventCode != "student_check" {
 			continue
 		}
 
 		for _, slot := range event.EventSlots {
-			start, _ := time.Parse(time.RFC3339, slot.Start)
-			end, _ := time.Parse(time.RFC3339, slot.End)
+			slots = append(slots, toReviewSlot(event, slot))
+		}
 
-			schoolShortName := ""
-			if slot.School.ShortName != "" {
-				schoolShortName = slot.School.ShortName
-			}
+		// Process bookings (already booked reviews)
+		for _, booking := range event.Bookings {
+			bookings = append(bookings, toReviewBooking(event, booking))
+		}
+	}
 
-			reviewSlot := ReviewSlot{
-				ID:       slot.ID,
-				Start:    start,
-				End:      end,
-				Type:     slot.Type,
-				IsOnline: false,
-				School:   schoolShortName,
-			}
+	return slots, bookings
+}
 
-			slots = append(slots, reviewSlot)
+// toReviewSlot maps a calendar slot to a ReviewSlot. The online flag and
+// video link live on the booking, so they are only set for booked slots.
+func toReviewSlot(event CalendarEvent, slot CalendarTimeSlot) ReviewSlot {
+	start, _ := time.Parse(time.RFC3339, slot.Start)
+	end, _ := time.Parse(time.RFC3339, slot.End)
+
+	s := ReviewSlot{
+		ID:     slot.ID,
+		Start:  start,
+		End:    end,
+		Type:   slot.Type,
+		Role:   eventRole(event, slot.Event.EventUserRole),
+		School: slot.School.ShortName,
+	}
+	for _, b := range event.Bookings {
+		if b.EventSlot.ID == slot.ID || b.EventSlotID == slot.ID {
+			s.IsOnline = b.IsOnline
+			s.VCLink = b.VCLinkURL
+			break
 		}
+	}
+	return s
+}
 
-		// Process bookings (already booked reviews)
-		for _, booking := range event.Bookings {
-			start, _ := time.Parse(time.RFC3339, booking.EventSlot.Start)
-			end, _ := time.Parse(time.RFC3339, booking.EventSlot.End)
-
-			reviewBooking := ReviewBooking{
-				ID:            booking.ID,
-				SlotID:        booking.EventSlot.ID,
-				Start:         start,
-				End:           end,
-				ProjectName:   booking.Task.GoalName,
-				VerifierLogin:  booking.VerifierUser.Login,
-				IsOnline:      booking.IsOnline,
-				Status:        booking.BookingStatus,
-			}
+// toReviewBooking maps a calendar booking to a ReviewBooking
+func toReviewBooking(event CalendarEvent, booking CalendarBooking) ReviewBooking {
+	start, _ := time.Parse(time.RFC3339, booking.EventSlot.Start)
+	end, _ := time.Parse(time.RFC3339, booking.EventSlot.End)
+
+	b := ReviewBooking{
+		ID:             booking.ID,
+		SlotID:         booking.EventSlot.ID,
+		Start:          start,
+		End:            end,
+		ProjectName:    booking.Task.GoalName,
+		VerifierLogin:  booking.VerifierUser.Login,
+		IsOnline:       booking.IsOnline,
+		Status:         booking.BookingStatus,
+		Role:           eventRole(event, booking.EventSlot.Event.EventUserRole),
+		AnswerID:       booking.AnswerID,
+		VCLink:         booking.VCLinkURL,
+		TaskID:         booking.Task.ID,
+		TaskTitle:      booking.Task.Title,
+		GoalID:         booking.Task.GoalID,
+		AssignmentType: booking.Task.AssignmentType,
+	}
+	if attrs := booking.Task.StudentTaskAdditionalAttributes; attrs != nil {
+		b.Cookies = attrs.CookiesCount
+	}
 
-			bookings = append(bookings, reviewBooking)
+	if info := booking.VerifiableInfo; info != nil {
+		if info.Team != nil {
+			b.TeamName = info.Team.Name
+		}
+		for _, s := range info.VerifiableStudents {
+			b.Reviewees = append(b.Reviewees, Participant{
+				UserID:           s.UserID,
+				Login:            s.Login,
+				Level:            s.LevelCode,
+				Campus:           s.School.ShortName,
+				IsTeamLead:       s.IsTeamLead != nil && *s.IsTeamLead,
+				Cookies:          s.CookiesCount,
+				CodeReviewPoints: s.CodeReviewPoints,
+			})
 		}
 	}
 
-	return slots, bookings, nil
+	if team := booking.Team; team != nil {
+		if b.TeamName == "" {
+			b.TeamName = team.TeamName
+		}
+		if team.TeamLead.Login != "" {
+			b.Team = append(b.Team, teamParticipant(team.TeamLead, true))
+		}
+		for _, m := range team.Members {
+			if m.Login != team.TeamLead.Login {
+				b.Team = append(b.Team, teamParticipant(m, false))
+			}
+		}
+	}
+	return b
+}
+
+func teamParticipant(m ProjectTeamMember, lead bool) Participant {
+	return Participant{
+		UserID:           m.ID,
+		Login:            m.Login,
+		Level:            m.UserExperience.Level.Range.LevelCode,
+		Campus:           m.ActiveSchoolShortName,
+		IsTeamLead:       lead,
+		Cookies:          m.UserExperience.CookiesCount,
+		CodeReviewPoints: m.UserExperience.CodeReviewPoints,
+	}
+}
+
+// eventRole tells from a review event which side we are on. Events about
+// our own code carry a studentCodeReview; for the others the platform
+// reports our role on the event, and only a verifiable role means we are
+// the ones being reviewed.
+func eventRole(event CalendarEvent, eventUserRole string) Role {
+	if event.StudentCodeReview != nil || strings.Contains(strings.ToUpper(eventUserRole), "VERIFIABLE") {
+		return RoleVerifiable
+	}
+	return RoleVerifier
 }
 
 // GetAvailableReviewSlots fetches only available (free) review slots
@@ -109,8 +233,9 @@ func (c *Client) GetAvailableReviewSlots(ctx context.Context, from, to time.Time
 	return available, nil
 }
 
-// GetBookedReviews fetches only booked review slots
-func (c *Client) GetBookedReviews(ctx context.Context, from, to time.Time) ([]ReviewBooking, error) {
+// GetBookedReviews fetches only booked review slots. If roles are given,
+// only bookings where we have one of them are returned.
+func (c *Client) GetBookedReviews(ctx context.Context, from, to time.Time, roles ...Role) ([]ReviewBooking, error) {
 	_, bookings, err := c.GetReviewSlots(ctx, from, to)
 	if err != nil {
 		return nil, err
@@ -126,16 +251,81 @@ func (c *Client) GetBookedReviews(ctx context.Context, from, to time.Time) ([]Re
 		}
 	}
 
-	return result, nil
+	if len(roles) == 0 {
+		return result, nil
+	}
+	return FilterBookings(result, roles...), nil
+}
+
+// FilterBookings returns the bookings where we have one of the roles
+func FilterBookings(bookings []ReviewBooking, roles ...Role) []ReviewBooking {
+	var result []ReviewBooking
+	for _, b := range bookings {
+		for _, r := range roles {
+			if b.Role == r {
+				result = append(result, b)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// SplitBookings separates the reviews we conduct from the reviews of our
+// own projects
+func SplitBookings(bookings []ReviewBooking) (reviewing, reviewed []ReviewBooking) {
+	return FilterBookings(bookings, RoleVerifier), FilterBookings(bookings, RoleVerifiable)
+}
+
+// FindReviewBooking looks up a booking by ID between from and to
+func (c *Client) FindReviewBooking(ctx context.Context, bookingID string, from, to time.Time) (*ReviewBooking, error) {
+	_, bookings, err := c.GetReviewSlots(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range bookings {
+		if b.ID == bookingID {
+			return &b, nil
+		}
+	}
+	return nil, fmt.Errorf("booking %s not found between %s and %s", bookingID,
+		from.Format("2006-01-02"), to.Format("2006-01-02"))
 }
 
-// AddReviewSlot adds a new review slot to the timetable
+// AddReviewSlot adds a new review slot to the timetable.
+// The slot filter, if set, runs first and may clip the slot into several
+// parts, each added as a slot of its own. Every part is validated with
+// ValidateSlot unless the client was created with WithoutSlotValidation,
+// and checked against the conflict policy, which returns a *ConflictError
+// for blocking conflicts. On error the slots added before it are returned.
 func (c *Client) AddReviewSlot(ctx context.Context, start, end time.Time) ([]ReviewSlot, error) {
-	startStr := start.Format("2006-01-02T15:04:05.000Z")
-	endStr := end.Format("2006-01-02T15:04:05.000Z")
+	parts, err := c.filterSlot(Interval{Start: start, End: end}, true)
+	if err != nil {
+		return nil, err
+	}
+	var slots []ReviewSlot
+	for _, p := range parts {
+		added, err := c.addReviewSlot(ctx, p.Start, p.End)
+		slots = append(slots, added...)
+		if err != nil {
+			return slots, err
+		}
+	}
+	return slots, nil
+}
+
+// addReviewSlot adds a single slot that already passed the slot filter
+func (c *Client) addReviewSlot(ctx context.Context, start, end time.Time) ([]ReviewSlot, error) {
+	if err := c.checkSlot(ctx, Interval{Start: start, End: end}); err != nil {
+		return nil, err
+	}
+
+	startStr := start.UTC().Format("2006-01-02T15:04:05.000Z")
+	endStr := end.UTC().Format("2006-01-02T15:04:05.000Z")
 
 	resp, err := c.AddEventToTimetable(ctx, startStr, endStr)
 	if err != nil {
+		c.observe(Mutation{Op: MutationAdd, Requested: &Interval{Start: start, End: end}}, err)
 		return nil, err
 	}
 
@@ -146,68 +336,75 @@ func (c *Client) AddReviewSlot(ctx context.Context, start, end time.Time) ([]Rev
 		}
 
 		for _, slot := range event.EventSlots {
-			s, _ := time.Parse(time.RFC3339, slot.Start)
-			e, _ := time.Parse(time.RFC3339, slot.End)
-
-			schoolShortName := ""
-			if slot.School.ShortName != "" {
-				schoolShortName = slot.School.ShortName
-			}
-
-			reviewSlot := ReviewSlot{
-				ID:       slot.ID,
-				Start:    s,
-				End:      e,
-				Type:     slot.Type,
-				IsOnline: false,
-				School:   schoolShortName,
-			}
-
-			slots = append(slots, reviewSlot)
+			slots = append(slots, toReviewSlot(event, slot))
 		}
 	}
 
+	c.observe(Mutation{Op: MutationAdd, Requested: &Interval{Start: start, End: end}, After: slots}, nil)
 	return slots, nil
 }
 
-// UpdateReviewSlot changes the time of an existing review slot
+// UpdateReviewSlot changes the time of an existing review slot.
+// The new times have to pass the slot filter unchanged. They are validated
+// with ValidateSlot first unless the client was created with
+// WithoutSlotValidation, and checked against the conflict policy, which
+// returns a *ConflictError for blocking conflicts.
 func (c *Client) UpdateReviewSlot(ctx context.Context, slotID string, newStart, newEnd time.Time) (*ReviewSlot, error) {
-	startStr := newStart.Format("2006-01-02T15:04:05.000Z")
-	endStr := newEnd.Format("2006-01-02T15:04:05.000Z")
+	return c.updateReviewSlot(ctx, slotID, newStart, newEnd, nil)
+}
+
+// updateReviewSlot is UpdateReviewSlot with the slot's previous state, if
+// the caller already has it
+func (c *Client) updateReviewSlot(ctx context.Context, slotID string, newStart, newEnd time.Time, before *ReviewSlot) (*ReviewSlot, error) {
+	if _, err := c.filterSlot(Interval{Start: newStart, End: newEnd}, false); err != nil {
+		return nil, err
+	}
+	if err := c.checkSlot(ctx, Interval{Start: newStart, End: newEnd}, slotID); err != nil {
+		return nil, err
+	}
+
+	m := Mutation{
+		Op:        MutationUpdate,
+		SlotID:    slotID,
+		Requested: &Interval{Start: newStart, End: newEnd},
+		Before:    c.preImage(ctx, slotID, before),
+	}
+
+	startStr := newStart.UTC().Format("2006-01-02T15:04:05.000Z")
+	endStr := newEnd.UTC().Format("2006-01-02T15:04:05.000Z")
 
 	resp, err := c.ChangeEventSlot(ctx, slotID, startStr, endStr)
 	if err != nil {
+		c.observe(m, err)
 		return nil, err
 	}
 
 	// Find the updated slot
-	for _, event := range resp.Student.ChangeEventSlot.EventSlots {
-		if event.ID == slotID {
-			start, _ := time.Parse(time.RFC3339, event.Start)
-			end, _ := time.Parse(time.RFC3339, event.End)
-
-			schoolShortName := ""
-			if event.School.ShortName != "" {
-				schoolShortName = event.School.ShortName
-			}
-
-			return &ReviewSlot{
-				ID:       event.ID,
-				Start:    start,
-				End:      end,
-				Type:     event.Type,
-				IsOnline: false,
-				School:   schoolShortName,
-			}, nil
+	event := resp.Student.ChangeEventSlot
+	for _, slot := range event.EventSlots {
+		if slot.ID == slotID {
+			s := toReviewSlot(event, slot)
+			m.After = []ReviewSlot{s}
+			c.observe(m, nil)
+			return &s, nil
 		}
 	}
 
+	c.observe(m, nil)
 	return nil, fmt.Errorf("updated slot not found in response")
 }
 
 // RemoveReviewSlot cancels/deletes a review slot
 func (c *Client) RemoveReviewSlot(ctx context.Context, slotID string) error {
+	return c.removeReviewSlot(ctx, slotID, nil)
+}
+
+// removeReviewSlot is RemoveReviewSlot with the slot's previous state, if
+// the caller already has it
+func (c *Client) removeReviewSlot(ctx context.Context, slotID string, before *ReviewSlot) error {
+	m := Mutation{Op: MutationRemove, SlotID: slotID, Before: c.preImage(ctx, slotID, before)}
 	_, err := c.DeleteEventSlot(ctx, slotID)
+	c.observe(m, err)
 	return err
 }
 
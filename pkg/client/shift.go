@@ -0,0 +1,129 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// ErrCollision is returned for a slot that cannot be moved because its new
+// times overlap a booking, another calendar event or a slot that stays put
+var ErrCollision = errors.New("collision")
+
+// ShiftResult is the outcome of moving one slot in ShiftReviewSlots
+type ShiftResult struct {
+	Slot   ReviewSlot  // the slot as it was before the shift
+	Target Interval    // where it was supposed to go
+	Moved  *ReviewSlot // the slot after the move, nil if it was not moved
+	Err    error
+}
+
+// obstacle is something in the calendar a shifted slot must not overlap
+type obstacle struct {
+	Interval
+	What string
+}
+
+// ShiftReviewSlots moves every free slot that starts within [from, to) by
+// the given offset. Slots are moved one at a time, those furthest in the
+// direction of the shift first, so that a slot never collides with one that
+// is about to move out of its way. A slot whose new times would overlap a
+// booking, a slot that is not moving or a calendar event the client's
+// conflict policy blocks is left alone with an error wrapping ErrCollision.
+// Every result is returned, sorted by the original start time.
+func (c *Client) ShiftReviewSlots(ctx context.Context, from, to time.Time, by time.Duration) ([]ShiftResult, error) {
+	// Look far enough around the range to see everything the moved slots
+	// could run into
+	longest := c.slotRules.MaxDuration
+	if longest <= 0 {
+		longest = DefaultSlotRules.MaxDuration
+	}
+	lo, hi := from, to.Add(longest)
+	if by < 0 {
+		lo = lo.Add(by)
+	} else {
+		hi = hi.Add(by)
+	}
+	resp, err := c.GetCalendarEvents(ctx, lo.UTC().Format("2006-01-02T15:04:05.000Z"), hi.UTC().Format("2006-01-02T15:04:05.000Z"))
+	if err != nil {
+		return nil, err
+	}
+
+	var static []obstacle
+	var others []CalendarEvent
+	var results []ShiftResult
+	for _, event := range resp.CalendarEventS21.GetMyCalendarEvents {
+		if event.EventCode != "student_check" {
+			others = append(others, event)
+			continue
+		}
+		for _, s := range event.EventSlots {
+			start, _ := time.Parse(time.RFC3339, s.Start)
+			end, _ := time.Parse(time.RFC3339, s.End)
+			slot := ReviewSlot{ID: s.ID, Start: start, End: end, Type: s.Type}
+			if slot.Type == SlotTypeFree && !start.Before(from) && start.Before(to) {
+				results = append(results, ShiftResult{Slot: slot, Target: Interval{Start: start.Add(by), End: end.Add(by)}})
+				continue
+			}
+			static = append(static, obstacle{slot.Interval(), fmt.Sprintf("%s slot %s", slot.Type, slot.ID)})
+		}
+		for _, b := range event.Bookings {
+			start, _ := time.Parse(time.RFC3339, b.EventSlot.Start)
+			end, _ := time.Parse(time.RFC3339, b.EventSlot.End)
+			static = append(static, obstacle{Interval{Start: start, End: end}, fmt.Sprintf("booking of %s", b.Task.GoalName)})
+		}
+	}
+	busy := BusyEvents(others)
+
+	sort.Slice(results, func(a, b int) bool { return results[a].Slot.Start.Before(results[b].Slot.Start) })
+	if by == 0 {
+		return results, nil
+	}
+
+	// position returns where a moving slot is right now
+	position := func(r ShiftResult) Interval {
+		if r.Moved != nil {
+			return r.Moved.Interval()
+		}
+		return r.Slot.Interval()
+	}
+
+	for k := range results {
+		i := k
+		if by > 0 {
+			i = len(results) - 1 - k
+		}
+		r := &results[i]
+
+		for _, o := range static {
+			if o.Overlaps(r.Target) {
+				r.Err = fmt.Errorf("%w with %s (%s - %s)", ErrCollision, o.What,
+					o.Start.Format("2006-01-02 15:04"), o.End.Format("15:04"))
+				break
+			}
+		}
+		if r.Err == nil {
+			// Warnings are left to the checks of the update itself
+			if blocked, _ := c.conflictPolicy.Check(r.Target, busy); len(blocked) > 0 {
+				r.Err = fmt.Errorf("%w with %s", ErrCollision, blocked[0])
+			}
+		}
+		for j, other := range results {
+			if r.Err != nil {
+				break
+			}
+			if j != i && position(other).Overlaps(r.Target) {
+				r.Err = fmt.Errorf("%w with free slot %s that could not be moved", ErrCollision, other.Slot.ID)
+			}
+		}
+		if r.Err != nil {
+			continue
+		}
+
+		r.Moved, r.Err = c.GuardedUpdateReviewSlot(ctx, r.Slot, r.Target.Start, r.Target.End)
+	}
+
+	return results, nil
+}
@@ -0,0 +1,217 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrTxDone is returned by the mutations of a transaction that was already
+// committed or rolled back
+var ErrTxDone = errors.New("transaction already committed or rolled back")
+
+// TxStep is a slot mutation applied within a transaction
+type TxStep struct {
+	Op     MutationOp
+	SlotID string       // slot updated or removed
+	Before *ReviewSlot  // the slot before an update or remove
+	After  []ReviewSlot // slots created by an add, or the updated slot
+}
+
+// Compensation is the undo of one TxStep during a rollback
+type Compensation struct {
+	Step     TxStep
+	Restored []ReviewSlot // slots moved back or recreated
+	Err      error        // why the step could not be rolled back
+}
+
+// RolledBack reports whether the step was undone
+func (c Compensation) RolledBack() bool {
+	return c.Err == nil
+}
+
+// TxReport describes a rollback
+type TxReport struct {
+	Cause         error          // error that caused the rollback, nil for an explicit one
+	Applied       []TxStep       // steps applied before the rollback, in order
+	Compensations []Compensation // undos in the order they ran, the last step first
+}
+
+// Complete reports whether every applied step was rolled back
+func (r *TxReport) Complete() bool {
+	return len(r.Failed()) == 0
+}
+
+// Failed returns the compensations that did not succeed; the calendar still
+// holds their steps
+func (r *TxReport) Failed() []Compensation {
+	var failed []Compensation
+	for _, c := range r.Compensations {
+		if !c.RolledBack() {
+			failed = append(failed, c)
+		}
+	}
+	return failed
+}
+
+// Tx groups slot mutations so that they can be undone together. Every
+// mutation is recorded with the slot's previous state; Rollback applies
+// compensating mutations in reverse order. A Tx is safe for concurrent use.
+type Tx struct {
+	c     *Client
+	mu    sync.Mutex
+	steps []TxStep
+	done  bool
+}
+
+// Begin starts a transaction
+func (c *Client) Begin() *Tx {
+	return &Tx{c: c}
+}
+
+// Transaction runs fn in a transaction. If fn returns an error the
+// transaction is rolled back and the report describes the rollback;
+// otherwise it is committed and the report is nil.
+func (c *Client) Transaction(ctx context.Context, fn func(tx *Tx) error) (*TxReport, error) {
+	tx := c.Begin()
+	if err := fn(tx); err != nil {
+		report := tx.Rollback(ctx)
+		report.Cause = err
+		return report, err
+	}
+	tx.Commit()
+	return nil, nil
+}
+
+func (tx *Tx) record(step TxStep) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.steps = append(tx.steps, step)
+}
+
+func (tx *Tx) check() error {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	if tx.done {
+		return ErrTxDone
+	}
+	return nil
+}
+
+// AddReviewSlot adds a slot that a rollback removes again
+func (tx *Tx) AddReviewSlot(ctx context.Context, start, end time.Time) ([]ReviewSlot, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	slots, err := tx.c.AddReviewSlot(ctx, start, end)
+	if len(slots) > 0 {
+		tx.record(TxStep{Op: MutationAdd, After: slots})
+	}
+	return slots, err
+}
+
+// UpdateReviewSlot moves a free slot that a rollback moves back. The slot is
+// looked up first to learn its current times; booked slots are refused with
+// ErrSlotBooked, since moving them would move the review.
+func (tx *Tx) UpdateReviewSlot(ctx context.Context, slotID string, newStart, newEnd time.Time) (*ReviewSlot, error) {
+	if err := tx.check(); err != nil {
+		return nil, err
+	}
+	before, err := tx.c.lookupSlot(ctx, slotID)
+	if err != nil {
+		return nil, err
+	}
+	if before.Type != SlotTypeFree {
+		return nil, fmt.Errorf("%w: slot %s is %s", ErrSlotBooked, slotID, before.Type)
+	}
+	slot, err := tx.c.updateReviewSlot(ctx, slotID, newStart, newEnd, before)
+	if err != nil {
+		return nil, err
+	}
+	tx.record(TxStep{Op: MutationUpdate, SlotID: slotID, Before: before, After: []ReviewSlot{*slot}})
+	return slot, nil
+}
+
+// RemoveReviewSlot removes a free slot that a rollback recreates. Booked
+// slots are refused with ErrSlotBooked, since their review could not be
+// restored.
+func (tx *Tx) RemoveReviewSlot(ctx context.Context, slotID string) error {
+	if err := tx.check(); err != nil {
+		return err
+	}
+	before, err := tx.c.lookupSlot(ctx, slotID)
+	if err != nil {
+		return err
+	}
+	if before.Type != SlotTypeFree {
+		return fmt.Errorf("%w: slot %s is %s", ErrSlotBooked, slotID, before.Type)
+	}
+	if err := tx.c.removeReviewSlot(ctx, slotID, before); err != nil {
+		return err
+	}
+	tx.record(TxStep{Op: MutationRemove, SlotID: slotID, Before: before})
+	return nil
+}
+
+// Steps returns the mutations applied so far
+func (tx *Tx) Steps() []TxStep {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return append([]TxStep(nil), tx.steps...)
+}
+
+// Commit ends the transaction and keeps its changes
+func (tx *Tx) Commit() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.done = true
+}
+
+// Rollback ends the transaction and undoes its steps, the last one first:
+// created slots are removed, updated slots moved back and removed slots
+// recreated. Slots booked since are left alone with an error wrapping
+// ErrSlotBooked. A rollback of a finished transaction does nothing.
+func (tx *Tx) Rollback(ctx context.Context) *TxReport {
+	tx.mu.Lock()
+	report := &TxReport{}
+	if tx.done {
+		tx.mu.Unlock()
+		return report
+	}
+	tx.done = true
+	report.Applied = append([]TxStep(nil), tx.steps...)
+	tx.mu.Unlock()
+
+	for i := len(report.Applied) - 1; i >= 0; i-- {
+		report.Compensations = append(report.Compensations, tx.c.compensate(ctx, report.Applied[i]))
+	}
+	return report
+}
+
+// compensate undoes a single step
+func (c *Client) compensate(ctx context.Context, step TxStep) Compensation {
+	comp := Compensation{Step: step}
+	switch step.Op {
+	case MutationAdd:
+		var failed []error
+		for _, s := range step.After {
+			if err := c.GuardedRemoveReviewSlot(ctx, s); err != nil {
+				failed = append(failed, err)
+			}
+		}
+		comp.Err = errors.Join(failed...)
+	case MutationUpdate:
+		slot, err := c.GuardedUpdateReviewSlot(ctx, step.After[0], step.Before.Start, step.Before.End)
+		if slot != nil {
+			comp.Restored = []ReviewSlot{*slot}
+		}
+		comp.Err = err
+	case MutationRemove:
+		comp.Restored, comp.Err = c.AddReviewSlot(ctx, step.Before.Start, step.Before.End)
+	default:
+		comp.Err = fmt.Errorf("unknown mutation %q", step.Op)
+	}
+	return comp
+}
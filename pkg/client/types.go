@@ -69,8 +69,10 @@ type Level struct {
 }
 
 type UserExperience struct {
-	Level Level `json:"level"`
-	Typename string `json:"__typename"`
+	Level            Level  `json:"level"`
+	CookiesCount     int    `json:"cookiesCount"`
+	CodeReviewPoints int    `json:"codeReviewPoints"`
+	Typename         string `json:"__typename"`
 }
 
 // User in booking (simplified version)
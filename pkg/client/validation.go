@@ -0,0 +1,204 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SlotRule names a constraint on review slots
+type SlotRule string
+
+// Slot rules checked by SlotRules.Check
+const (
+	RuleMinDuration SlotRule = "min_duration"
+	RuleMaxDuration SlotRule = "max_duration"
+	RuleGranularity SlotRule = "granularity"
+	RuleLeadTime    SlotRule = "lead_time"
+	RuleMaxHorizon  SlotRule = "max_horizon"
+	RuleOverlap     SlotRule = "overlap"
+)
+
+// SlotRules are client-side constraints on review slots, checked before a
+// mutation is sent. A zero value disables the corresponding check; a slot
+// must always end after it starts and not overlap another slot.
+type SlotRules struct {
+	MinDuration time.Duration // shortest allowed slot
+	MaxDuration time.Duration // longest allowed slot
+	Granularity time.Duration // start and end must be multiples of this
+	MinLeadTime time.Duration // how far from now a slot must start at least
+	MaxHorizon  time.Duration // how far from now a slot may end at most
+}
+
+// DefaultSlotRules are the conservative limits the CLI validates slots
+// against. They are not documented by the platform, so a Client only
+// applies them when given with WithSlotRules.
+var DefaultSlotRules = SlotRules{
+	MinDuration: 30 * time.Minute,
+	MaxDuration: 12 * time.Hour,
+	Granularity: 15 * time.Minute,
+	MinLeadTime: 30 * time.Minute,
+	MaxHorizon:  14 * 24 * time.Hour,
+}
+
+// RuleViolation describes a single broken constraint
+type RuleViolation struct {
+	Rule    SlotRule
+	Message string
+}
+
+// ValidationError is returned when a slot breaks one or more slot rules
+type ValidationError struct {
+	Slot       Interval
+	Violations []RuleViolation
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Violations))
+	for i, v := range e.Violations {
+		msgs[i] = v.Message
+	}
+	return fmt.Sprintf("invalid slot %s - %s: %s",
+		e.Slot.Start.Format("2006-01-02 15:04"), e.Slot.End.Format("2006-01-02 15:04"),
+		strings.Join(msgs, "; "))
+}
+
+// Has reports whether the given rule is among the violations
+func (e *ValidationError) Has(rule SlotRule) bool {
+	for _, v := range e.Violations {
+		if v.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+// Check returns every rule the slot violates. existing holds the slots
+// already in the calendar; the slot must not overlap any of them.
+func (r SlotRules) Check(slot Interval, existing []ReviewSlot, now time.Time) []RuleViolation {
+	var violations []RuleViolation
+	add := func(rule SlotRule, format string, args ...interface{}) {
+		violations = append(violations, RuleViolation{Rule: rule, Message: fmt.Sprintf(format, args...)})
+	}
+
+	d := slot.Duration()
+	if d <= 0 {
+		add(RuleMinDuration, "end time must be after start time")
+	} else if r.MinDuration > 0 && d < r.MinDuration {
+		add(RuleMinDuration, "duration %s is shorter than the minimum of %s", d, r.MinDuration)
+	}
+	if r.MaxDuration > 0 && d > r.MaxDuration {
+		add(RuleMaxDuration, "duration %s is longer than the maximum of %s", d, r.MaxDuration)
+	}
+	if r.Granularity > 0 {
+		if !slot.Start.Truncate(r.Granularity).Equal(slot.Start) || !slot.End.Truncate(r.Granularity).Equal(slot.End) {
+			add(RuleGranularity, "start and end must be multiples of %s", r.Granularity)
+		}
+	}
+	if r.MinLeadTime > 0 && slot.Start.Before(now.Add(r.MinLeadTime)) {
+		add(RuleLeadTime, "slot must start at least %s from now", r.MinLeadTime)
+	}
+	if r.MaxHorizon > 0 && slot.End.After(now.Add(r.MaxHorizon)) {
+		add(RuleMaxHorizon, "slot must end within %s from now", r.MaxHorizon)
+	}
+	for _, s := range existing {
+		if s.Interval().Overlaps(slot) {
+			add(RuleOverlap, "overlaps %s slot %s (%s - %s)", s.Type, s.ID,
+				s.Start.Format("2006-01-02 15:04"), s.End.Format("15:04"))
+		}
+	}
+
+	return violations
+}
+
+// ValidateSlot checks a slot against the client's slot rules and the slots
+// already in the calendar. The slot with ID ignoreSlotID, if any, is left out
+// of the overlap check so that a slot can be validated against its own new
+// times. It returns a *ValidationError listing every violated rule.
+func (c *Client) ValidateSlot(ctx context.Context, slot Interval, ignoreSlotID string) error {
+	var events []CalendarEvent
+	if !slot.IsEmpty() {
+		var err error
+		if events, err = c.slotEvents(ctx, slot); err != nil {
+			return err
+		}
+	}
+	return c.validate(slot, events, ignoreSlotID)
+}
+
+// slotEvents fetches the calendar events around a slot for the checks
+func (c *Client) slotEvents(ctx context.Context, slot Interval) ([]CalendarEvent, error) {
+	resp, err := c.GetCalendarEvents(ctx, slot.Start.UTC().Format("2006-01-02T15:04:05.000Z"), slot.End.UTC().Format("2006-01-02T15:04:05.000Z"))
+	if err != nil {
+		return nil, fmt.Errorf("fetch existing slots: %w", err)
+	}
+	return resp.CalendarEventS21.GetMyCalendarEvents, nil
+}
+
+func (c *Client) validate(slot Interval, events []CalendarEvent, ignore ...string) error {
+	slots, _ := reviewSlotsFromEvents(events)
+	var existing []ReviewSlot
+	for _, s := range slots {
+		if !contains(ignore, s.ID) {
+			existing = append(existing, s)
+		}
+	}
+
+	if violations := c.slotRules.Check(slot, existing, time.Now()); len(violations) > 0 {
+		return &ValidationError{Slot: slot, Violations: violations}
+	}
+	return nil
+}
+
+// checkSlot runs the client-side checks enabled on the client before a slot
+// is created or moved to new times, with a single calendar fetch: the slot
+// rules unless validation is disabled, then the conflict policy. Conflicts
+// the policy only warns about go to the conflict handler. The slots in
+// ignore are left out of both the overlap check and the conflict check.
+func (c *Client) checkSlot(ctx context.Context, slot Interval, ignore ...string) error {
+	validate := !c.skipSlotValidation
+	conflicts := c.conflictPolicy.enabled() && !slot.IsEmpty()
+	if !validate && !conflicts {
+		return nil
+	}
+
+	var events []CalendarEvent
+	if !slot.IsEmpty() {
+		var err error
+		if events, err = c.slotEvents(ctx, slot); err != nil {
+			return err
+		}
+	}
+
+	if validate {
+		if err := c.validate(slot, events, ignore...); err != nil {
+			return err
+		}
+	}
+	if conflicts {
+		var busy []BusyEvent
+		for _, e := range BusyEvents(events) {
+			if !contains(ignore, e.ID) {
+				busy = append(busy, e)
+			}
+		}
+		blocked, warned := c.conflictPolicy.Check(slot, busy)
+		if len(blocked) > 0 {
+			return &ConflictError{Slot: slot, Conflicts: blocked}
+		}
+		if len(warned) > 0 && c.conflictHandler != nil {
+			c.conflictHandler(&ConflictError{Slot: slot, Conflicts: warned})
+		}
+	}
+	return nil
+}
+
+func contains(ids []string, id string) bool {
+	for _, x := range ids {
+		if x != "" && x == id {
+			return true
+		}
+	}
+	return false
+}
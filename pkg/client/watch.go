@@ -0,0 +1,333 @@
+package client
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// ChangeType names a change between two calendar snapshots
+type ChangeType string
+
+// Changes reported by DiffCalendar and WatchCalendar
+const (
+	SlotAdded        ChangeType = "SlotAdded"
+	SlotRemoved      ChangeType = "SlotRemoved"
+	SlotResized      ChangeType = "SlotResized"
+	SlotBooked       ChangeType = "SlotBooked"
+	SlotFreed        ChangeType = "SlotFreed"
+	BookingCreated   ChangeType = "BookingCreated"
+	BookingCancelled ChangeType = "BookingCancelled"
+	BookingUpdated   ChangeType = "BookingUpdated"
+	ExamAdded        ChangeType = "ExamAdded"
+	ExamRemoved      ChangeType = "ExamRemoved"
+	ExamChanged      ChangeType = "ExamChanged"
+	EventAdded       ChangeType = "EventAdded"
+	EventRemoved     ChangeType = "EventRemoved"
+	EventChanged     ChangeType = "EventChanged"
+	// WatchFailed carries a polling error; the watcher keeps going
+	WatchFailed ChangeType = "WatchFailed"
+)
+
+// CalendarChange is one difference between two calendar snapshots. Only the
+// before and after fields of the changed kind of item are set: a nil before
+// state means the item appeared, a nil after state that it went away.
+type CalendarChange struct {
+	Type          ChangeType     `json:"type"`
+	DetectedAt    time.Time      `json:"detectedAt"`
+	SlotBefore    *ReviewSlot    `json:"slotBefore,omitempty"`
+	SlotAfter     *ReviewSlot    `json:"slotAfter,omitempty"`
+	BookingBefore *ReviewBooking `json:"bookingBefore,omitempty"`
+	BookingAfter  *ReviewBooking `json:"bookingAfter,omitempty"`
+	EventBefore   *BusyEvent     `json:"eventBefore,omitempty"`
+	EventAfter    *BusyEvent     `json:"eventAfter,omitempty"`
+	Err           error          `json:"-"`
+}
+
+// CalendarSnapshot is the state of the calendar at one point in time
+type CalendarSnapshot struct {
+	TakenAt  time.Time
+	Slots    map[string]ReviewSlot
+	Bookings map[string]ReviewBooking
+	Events   map[string]BusyEvent // exams, activities, penalties and other non-review events
+}
+
+// NewCalendarSnapshot indexes calendar events by ID
+func NewCalendarSnapshot(events []CalendarEvent, takenAt time.Time) *CalendarSnapshot {
+	s := &CalendarSnapshot{
+		TakenAt:  takenAt,
+		Slots:    make(map[string]ReviewSlot),
+		Bookings: make(map[string]ReviewBooking),
+		Events:   make(map[string]BusyEvent),
+	}
+	slots, bookings := reviewSlotsFromEvents(events)
+	for _, slot := range slots {
+		s.Slots[slot.ID] = slot
+	}
+	for _, b := range bookings {
+		s.Bookings[b.ID] = b
+	}
+	for _, e := range BusyEvents(events) {
+		if e.Kind != EventSlot && e.Kind != EventReview {
+			s.Events[e.ID] = e
+		}
+	}
+	return s
+}
+
+// DiffCalendar returns the changes from prev to cur. The result is sorted by
+// the start of the changed item, then by type and ID, so the same pair of
+// snapshots always gives the same list.
+func DiffCalendar(prev, cur *CalendarSnapshot) []CalendarChange {
+	var changes []CalendarChange
+	add := func(c CalendarChange) {
+		c.DetectedAt = cur.TakenAt
+		changes = append(changes, c)
+	}
+
+	for id, before := range prev.Slots {
+		after, ok := cur.Slots[id]
+		if !ok {
+			add(CalendarChange{Type: SlotRemoved, SlotBefore: &before})
+			continue
+		}
+		if !before.Interval().Equal(after.Interval()) {
+			add(CalendarChange{Type: SlotResized, SlotBefore: &before, SlotAfter: &after})
+		}
+		if before.Type == SlotTypeFree && after.Type != SlotTypeFree {
+			add(CalendarChange{Type: SlotBooked, SlotBefore: &before, SlotAfter: &after})
+		} else if before.Type != SlotTypeFree && after.Type == SlotTypeFree {
+			add(CalendarChange{Type: SlotFreed, SlotBefore: &before, SlotAfter: &after})
+		}
+	}
+	for id, after := range cur.Slots {
+		if _, ok := prev.Slots[id]; !ok {
+			add(CalendarChange{Type: SlotAdded, SlotAfter: &after})
+		}
+	}
+
+	for id, before := range prev.Bookings {
+		after, ok := cur.Bookings[id]
+		if !ok {
+			add(CalendarChange{Type: BookingCancelled, BookingBefore: &before})
+			continue
+		}
+		if before.Status != after.Status || !before.Interval().Equal(after.Interval()) ||
+			before.IsOnline != after.IsOnline {
+			add(CalendarChange{Type: BookingUpdated, BookingBefore: &before, BookingAfter: &after})
+		}
+	}
+	for id, after := range cur.Bookings {
+		if _, ok := prev.Bookings[id]; !ok {
+			add(CalendarChange{Type: BookingCreated, BookingAfter: &after})
+		}
+	}
+
+	for id, before := range prev.Events {
+		after, ok := cur.Events[id]
+		if !ok {
+			add(CalendarChange{Type: eventChange(before.Kind, ExamRemoved, EventRemoved), EventBefore: &before})
+			continue
+		}
+		if !before.Interval.Equal(after.Interval) || before.Kind != after.Kind || before.Title != after.Title {
+			add(CalendarChange{Type: eventChange(after.Kind, ExamChanged, EventChanged), EventBefore: &before, EventAfter: &after})
+		}
+	}
+	for id, after := range cur.Events {
+		if _, ok := prev.Events[id]; !ok {
+			add(CalendarChange{Type: eventChange(after.Kind, ExamAdded, EventAdded), EventAfter: &after})
+		}
+	}
+
+	sort.Slice(changes, func(a, b int) bool {
+		sa, ia := changes[a].subject()
+		sb, ib := changes[b].subject()
+		if !sa.Equal(sb) {
+			return sa.Before(sb)
+		}
+		if changes[a].Type != changes[b].Type {
+			return changes[a].Type < changes[b].Type
+		}
+		return ia < ib
+	})
+	return changes
+}
+
+func eventChange(kind EventKind, exam, other ChangeType) ChangeType {
+	if kind == EventExam {
+		return exam
+	}
+	return other
+}
+
+// subject returns the start and ID of the item a change is about, preferring
+// the state after the change
+func (c CalendarChange) subject() (time.Time, string) {
+	switch {
+	case c.SlotAfter != nil:
+		return c.SlotAfter.Start, c.SlotAfter.ID
+	case c.SlotBefore != nil:
+		return c.SlotBefore.Start, c.SlotBefore.ID
+	case c.BookingAfter != nil:
+		return c.BookingAfter.Start, c.BookingAfter.ID
+	case c.BookingBefore != nil:
+		return c.BookingBefore.Start, c.BookingBefore.ID
+	case c.EventAfter != nil:
+		return c.EventAfter.Start, c.EventAfter.ID
+	case c.EventBefore != nil:
+		return c.EventBefore.Start, c.EventBefore.ID
+	}
+	return time.Time{}, ""
+}
+
+// BeforeInterval returns the times of the changed item before the change,
+// and false if the item did not exist yet
+func (c CalendarChange) BeforeInterval() (Interval, bool) {
+	switch {
+	case c.SlotBefore != nil:
+		return c.SlotBefore.Interval(), true
+	case c.BookingBefore != nil:
+		return c.BookingBefore.Interval(), true
+	case c.EventBefore != nil:
+		return c.EventBefore.Interval, true
+	}
+	return Interval{}, false
+}
+
+// AfterInterval returns the times of the changed item after the change,
+// and false if the item no longer exists
+func (c CalendarChange) AfterInterval() (Interval, bool) {
+	switch {
+	case c.SlotAfter != nil:
+		return c.SlotAfter.Interval(), true
+	case c.BookingAfter != nil:
+		return c.BookingAfter.Interval(), true
+	case c.EventAfter != nil:
+		return c.EventAfter.Interval, true
+	}
+	return Interval{}, false
+}
+
+// Bounds of the adaptive polling delay, relative to the base interval
+const (
+	watchFastDivisor = 4 // after a change, poll this many times as often
+	watchSlowFactor  = 4 // while quiet or failing, slow down to at most this
+)
+
+// WatchCalendar polls the calendar between from and to and sends every
+// change between successive snapshots on the returned channel. The first
+// poll only sets the baseline. Polling speeds up right after a change and
+// slows down while nothing happens or requests fail; failures are sent as
+// WatchFailed changes. The channel is closed once ctx is done.
+func (c *Client) WatchCalendar(ctx context.Context, from, to time.Time, interval time.Duration) <-chan CalendarChange {
+	return c.watch(ctx, interval, func(time.Time) Interval {
+		return Interval{Start: from, End: to}
+	}, false)
+}
+
+// WatchUpcoming is WatchCalendar over a window that moves with time, from
+// now up to horizon ahead. Items leaving the window at its start or entering
+// it at its far end are not reported, see MovingWindowChanges.
+func (c *Client) WatchUpcoming(ctx context.Context, horizon, interval time.Duration) <-chan CalendarChange {
+	return c.watch(ctx, interval, func(now time.Time) Interval {
+		return Interval{Start: now, End: now.Add(horizon)}
+	}, true)
+}
+
+// watch runs the polling loop of WatchCalendar and WatchUpcoming, reading
+// the window of each poll from window
+func (c *Client) watch(ctx context.Context, interval time.Duration, window func(now time.Time) Interval, moving bool) <-chan CalendarChange {
+	ch := make(chan CalendarChange, 16)
+
+	go func() {
+		defer close(ch)
+
+		var prev *CalendarSnapshot
+		var prevTo time.Time
+		delay := interval
+		for {
+			now := time.Now()
+			w := window(now)
+			resp, err := c.GetCalendarEvents(ctx,
+				w.Start.UTC().Format("2006-01-02T15:04:05.000Z"), w.End.UTC().Format("2006-01-02T15:04:05.000Z"))
+			if ctx.Err() != nil {
+				return
+			}
+
+			if err != nil {
+				delay = slower(delay, interval)
+				select {
+				case ch <- CalendarChange{Type: WatchFailed, DetectedAt: time.Now(), Err: err}:
+				case <-ctx.Done():
+					return
+				}
+			} else {
+				cur := NewCalendarSnapshot(resp.CalendarEventS21.GetMyCalendarEvents, now)
+				var changes []CalendarChange
+				if prev != nil {
+					changes = DiffCalendar(prev, cur)
+					if moving {
+						changes = MovingWindowChanges(changes, now, prevTo)
+					}
+				}
+				prev, prevTo = cur, w.End
+
+				if len(changes) > 0 {
+					delay = interval / watchFastDivisor
+				} else {
+					delay = slower(delay, interval)
+				}
+				for _, change := range changes {
+					select {
+					case ch <- change:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// MovingWindowChanges drops the changes that only come from a watched range
+// moving forward: items that ended by now and left it at the start, and
+// items that entered it at the far end, beyond prevTo, the end of the
+// previous poll's range
+func MovingWindowChanges(changes []CalendarChange, now, prevTo time.Time) []CalendarChange {
+	var kept []CalendarChange
+	for _, ch := range changes {
+		if _, exists := ch.AfterInterval(); !exists {
+			if iv, ok := ch.BeforeInterval(); ok && !iv.End.After(now) {
+				continue
+			}
+		}
+		if _, existed := ch.BeforeInterval(); !existed {
+			if iv, ok := ch.AfterInterval(); ok && !iv.Start.Before(prevTo) {
+				continue
+			}
+		}
+		kept = append(kept, ch)
+	}
+	return kept
+}
+
+// slower doubles the polling delay, staying between the base interval and
+// watchSlowFactor times it
+func slower(delay, interval time.Duration) time.Duration {
+	delay *= 2
+	if delay < interval {
+		delay = interval
+	}
+	if max := interval * watchSlowFactor; delay > max {
+		delay = max
+	}
+	return delay
+}
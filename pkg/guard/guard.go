@@ -0,0 +1,123 @@
+// Package guard implements policies that keep the review calendar in a
+// safe state, such as withdrawing free slots that start too soon. Policies
+// can be run once or periodically in a long-running loop.
+package guard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// ActionKind is what a policy did to a slot
+type ActionKind string
+
+const (
+	// ActionRemove deleted a slot
+	ActionRemove ActionKind = "remove"
+	// ActionTrim shortened a slot
+	ActionTrim ActionKind = "trim"
+	// ActionRestore re-added a slot withdrawn earlier
+	ActionRestore ActionKind = "restore"
+	// ActionAdd created a slot
+	ActionAdd ActionKind = "add"
+)
+
+// Action is a single change made by a policy
+type Action struct {
+	Policy string
+	Kind   ActionKind
+	SlotID string
+	Before client.Interval
+	After  *client.Interval // nil when the slot was removed
+	Reason string
+	Err    error // set when the change could not be made
+}
+
+func (a Action) String() string {
+	before := fmt.Sprintf("%s - %s", a.Before.Start.Format("2006-01-02 15:04"), a.Before.End.Format("15:04"))
+	var s string
+	switch a.Kind {
+	case ActionTrim:
+		s = fmt.Sprintf("[%s] trim slot %s: %s -> %s - %s (%s)", a.Policy, a.SlotID, before,
+			a.After.Start.Format("15:04"), a.After.End.Format("15:04"), a.Reason)
+	default:
+		s = fmt.Sprintf("[%s] %s slot %s: %s (%s)", a.Policy, a.Kind, a.SlotID, before, a.Reason)
+	}
+	if a.Err != nil {
+		s += fmt.Sprintf(": FAILED: %v", a.Err)
+	}
+	return s
+}
+
+// Policy is a rule that inspects the calendar and changes it if needed
+type Policy interface {
+	// Name identifies the policy in logs
+	Name() string
+	// Enforce brings the calendar in line with the policy and returns
+	// every change it made or attempted
+	Enforce(ctx context.Context, c *client.Client, now time.Time) ([]Action, error)
+}
+
+// RunOnce enforces every policy once, in order. A failing policy does not
+// stop the others; the first error is returned after all of them ran.
+func RunOnce(ctx context.Context, c *client.Client, policies []Policy, report func(Action)) error {
+	var firstErr error
+	for _, p := range policies {
+		actions, err := p.Enforce(ctx, c, time.Now())
+		for _, a := range actions {
+			report(a)
+		}
+		if err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", p.Name(), err)
+		}
+	}
+	return firstErr
+}
+
+// Run enforces the policies every interval until ctx is cancelled. Errors
+// are passed to onError and do not stop the loop. Every round gets its own
+// timeout so that a hanging request cannot stall the guard.
+func Run(ctx context.Context, c *client.Client, policies []Policy, interval, timeout time.Duration, report func(Action), onError func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		roundCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := RunOnce(roundCtx, c, policies, report)
+		cancel()
+		if err != nil && ctx.Err() == nil {
+			onError(err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// apply executes the planned actions against the API and records the
+// outcome of each on the action itself. Slots are re-checked right before
+// they are touched, so a slot booked in the meantime fails with
+// client.ErrSlotBooked instead of cancelling the review.
+func apply(ctx context.Context, c *client.Client, actions []Action) []Action {
+	for i, a := range actions {
+		slot := a.slot()
+		switch a.Kind {
+		case ActionRemove:
+			actions[i].Err = c.GuardedRemoveReviewSlot(ctx, slot)
+		case ActionTrim:
+			_, actions[i].Err = c.GuardedUpdateReviewSlot(ctx, slot, a.After.Start, a.After.End)
+		}
+	}
+	return actions
+}
+
+// slot returns the free slot the action applies to, as it was planned
+func (a Action) slot() client.ReviewSlot {
+	return client.ReviewSlot{ID: a.SlotID, Start: a.Before.Start, End: a.Before.End, Type: client.SlotTypeFree}
+}
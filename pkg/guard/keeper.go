@@ -0,0 +1,284 @@
+package guard
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/blackout"
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Window is a preferred time-of-day range for new slots, as offsets from
+// midnight
+type Window struct {
+	Start time.Duration
+	End   time.Duration
+}
+
+// Keeper keeps a rolling horizon of days covered: every day from today up
+// to Days ahead gets at least Target of review availability, added within
+// the preferred windows around other calendar events. Free and booked slots
+// both count as availability, so booked reviews are not topped up again.
+// Keeper only adds slots and never takes a day past Max; it leaves slots
+// it did not create alone. New slots stay out of Blackouts, if set.
+type Keeper struct {
+	Days        int
+	Target      time.Duration    // availability to keep per day
+	Max         time.Duration    // most availability per day, 0 for no cap
+	Windows     []Window         // preferred times of day, the whole day if empty
+	Buffer      time.Duration    // time kept free around other events
+	MinSlot     time.Duration    // shortest slot worth adding
+	Granularity time.Duration    // new slots start and end on multiples of this
+	Lead        time.Duration    // new slots start at least this far from now
+	Location    *time.Location   // zone days and windows are in
+	Quota       *Quota           // if set, days that reached a review cap are skipped
+	Blackouts   *blackout.Config // times never to add slots in, if set
+}
+
+// NewKeeper creates a keeper with the limits of DefaultSlotRules, in the
+// local zone
+func NewKeeper(days int, target, max time.Duration, windows []Window) Keeper {
+	rules := client.DefaultSlotRules
+	return Keeper{
+		Days:        days,
+		Target:      target,
+		Max:         max,
+		Windows:     windows,
+		MinSlot:     rules.MinDuration,
+		Granularity: rules.Granularity,
+		Lead:        rules.MinLeadTime,
+		Location:    time.Local,
+	}
+}
+
+// Name implements Policy
+func (k Keeper) Name() string {
+	return "keeper"
+}
+
+// DayCoverage is how much review availability a day has
+type DayCoverage struct {
+	Day       time.Time
+	Available time.Duration // free and booked slot time
+	Target    time.Duration
+}
+
+// Short reports whether the day is below the target
+func (d DayCoverage) Short() bool {
+	return d.Available < d.Target
+}
+
+func (k Keeper) days(now time.Time) []client.Interval {
+	t := now.In(k.Location)
+	first := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, k.Location)
+	var days []client.Interval
+	for d := 0; d < k.Days; d++ {
+		start := first.AddDate(0, 0, d)
+		days = append(days, client.Interval{Start: start, End: start.AddDate(0, 0, 1)})
+	}
+	return days
+}
+
+// Range returns the span of the days the keeper looks after
+func (k Keeper) Range(now time.Time) client.Interval {
+	days := k.days(now)
+	if len(days) == 0 {
+		return client.Interval{Start: now, End: now}
+	}
+	return client.Interval{Start: days[0].Start, End: days[len(days)-1].End}
+}
+
+// Coverage returns the availability of every day the keeper looks after
+func (k Keeper) Coverage(now time.Time, slots []client.ReviewSlot) []DayCoverage {
+	var coverage []DayCoverage
+	for _, day := range k.days(now) {
+		coverage = append(coverage, DayCoverage{Day: day.Start, Available: available(day, slots), Target: k.Target})
+	}
+	return coverage
+}
+
+// available sums the slot time within a day
+func available(day client.Interval, slots []client.ReviewSlot) time.Duration {
+	var ivs []client.Interval
+	for _, s := range slots {
+		if iv, ok := s.Interval().Intersect(day); ok {
+			ivs = append(ivs, iv)
+		}
+	}
+	var total time.Duration
+	for _, iv := range client.MergeIntervals(ivs) {
+		total += iv.Duration()
+	}
+	return total
+}
+
+// Blocked returns the blacked-out times within the days the keeper looks
+// after
+func (k Keeper) Blocked(now time.Time) ([]client.Interval, error) {
+	if k.Blackouts == nil {
+		return nil, nil
+	}
+	r := k.Range(now)
+	blocks, err := k.Blackouts.Blocks(r.Start, r.End)
+	if err != nil {
+		return nil, err
+	}
+	blocked := make([]client.Interval, len(blocks))
+	for i, b := range blocks {
+		blocked[i] = b.Interval
+	}
+	return blocked, nil
+}
+
+// Plan returns the slots to add to bring every short day up to the target.
+// slots are our review slots, busy every calendar event; our own slots and
+// reviews are kept clear of without the buffer, so new slots may extend
+// them. Nothing is planned within blocked. usage, if the keeper has a quota,
+// skips days that are full.
+func (k Keeper) Plan(now time.Time, slots []client.ReviewSlot, busy []client.BusyEvent, blocked []client.Interval, usage []Usage) []Action {
+	var own []client.Interval
+	for _, s := range slots {
+		own = append(own, s.Interval())
+	}
+	constraints := client.FreeTimeConstraints{
+		MinLength:   k.MinSlot,
+		MaxLength:   client.DefaultSlotRules.MaxDuration,
+		Buffer:      k.Buffer,
+		Granularity: k.Granularity,
+		Ignore:      []client.EventKind{client.EventSlot, client.EventReview},
+	}
+	earliest := now.Add(k.Lead)
+
+	var actions []Action
+	for _, day := range k.days(now) {
+		have := available(day, slots)
+		if have >= k.Target {
+			continue
+		}
+		if k.Quota != nil && k.Quota.fullReason(day.Start, usage) != "" {
+			continue
+		}
+		need := k.Target - have
+		room := time.Duration(-1)
+		if k.Max > 0 {
+			room = k.Max - have
+		}
+
+		for _, gap := range k.gaps(day, earliest, own, busy, blocked, constraints) {
+			if need <= 0 {
+				break
+			}
+			length := roundUp(need, k.Granularity)
+			if length < k.MinSlot {
+				length = k.MinSlot
+			}
+			if length > gap.Duration() {
+				length = gap.Duration()
+			}
+			if room >= 0 && length > room {
+				length = room.Truncate(max(k.Granularity, time.Minute))
+			}
+			if length < k.MinSlot || length <= 0 {
+				break
+			}
+
+			iv := client.Interval{Start: gap.Start, End: gap.Start.Add(length)}
+			actions = append(actions, Action{
+				Policy: k.Name(),
+				Kind:   ActionAdd,
+				Before: iv,
+				After:  &iv,
+				Reason: fmt.Sprintf("%s of %s available on %s", formatHours(have), formatHours(k.Target), day.Start.Format("Mon 2006-01-02")),
+			})
+			have += length
+			need -= length
+			if room >= 0 {
+				room -= length
+			}
+		}
+	}
+	return actions
+}
+
+// gaps returns the free time of a day within the windows, in order
+func (k Keeper) gaps(day client.Interval, earliest time.Time, own []client.Interval, busy []client.BusyEvent, blocked []client.Interval, c client.FreeTimeConstraints) []client.Interval {
+	windows := k.Windows
+	if len(windows) == 0 {
+		windows = []Window{{Start: 0, End: 24 * time.Hour}}
+	}
+
+	var result []client.Interval
+	for _, w := range windows {
+		iv := client.Interval{Start: day.Start.Add(w.Start), End: day.Start.Add(w.End)}
+		if iv.Start.Before(earliest) {
+			iv.Start = earliest
+		}
+		if iv.IsEmpty() {
+			continue
+		}
+		for _, part := range client.SubtractIntervals([]client.Interval{iv}, blocked) {
+			for _, gap := range client.SubtractIntervals(client.FindFree(part, busy, c), own) {
+				if gap.Duration() >= k.MinSlot {
+					result = append(result, gap)
+				}
+			}
+		}
+	}
+	return client.MergeIntervals(result)
+}
+
+func roundUp(d, granularity time.Duration) time.Duration {
+	if granularity <= 0 {
+		return d
+	}
+	return (d + granularity - 1) / granularity * granularity
+}
+
+func formatHours(d time.Duration) string {
+	return fmt.Sprintf("%dh%02dm", int(d.Hours()), int(d.Minutes())%60)
+}
+
+// Enforce implements Policy. New slots go through EnsureReviewSlot, so a
+// gap next to one of our free slots stretches that slot instead of adding
+// a fragment beside it.
+func (k Keeper) Enforce(ctx context.Context, c *client.Client, now time.Time) ([]Action, error) {
+	r := k.Range(now)
+	if r.IsEmpty() {
+		return nil, nil
+	}
+	from := r.Start
+	if k.Quota != nil {
+		from = k.Quota.periodStart(PeriodWeek, now)
+	}
+	slots, bookings, err := c.GetReviewSlots(ctx, from, r.End)
+	if err != nil {
+		return nil, err
+	}
+	busy, err := c.GetBusyEvents(ctx, r.Start.Add(-k.Buffer), r.End.Add(k.Buffer))
+	if err != nil {
+		return nil, err
+	}
+	blocked, err := k.Blocked(now)
+	if err != nil {
+		return nil, err
+	}
+	var usage []Usage
+	if k.Quota != nil {
+		usage = k.Quota.Usage(now, bookings)
+	}
+
+	actions := k.Plan(now, slots, busy, blocked, usage)
+	for i, a := range actions {
+		res, err := c.EnsureReviewSlot(ctx, a.Before.Start, a.Before.End)
+		switch {
+		case len(res.Added) > 0:
+			actions[i].SlotID = res.Added[0].ID
+		case len(res.Extended) > 0:
+			actions[i].SlotID = res.Extended[0].ID
+			actions[i].Reason += ", extended an adjacent slot"
+		}
+		actions[i].Err = err
+	}
+	return actions, nil
+}
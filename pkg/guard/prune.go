@@ -0,0 +1,88 @@
+package guard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// PruneSoon withdraws free slots that start within Lead from now, so that
+// nobody can book a review at the last minute. With Trim set, a slot that
+// is long enough keeps the part after the lead time instead of being
+// removed entirely.
+type PruneSoon struct {
+	Lead        time.Duration
+	Trim        bool
+	Granularity time.Duration // trimmed slots start on a multiple of this
+	MinDuration time.Duration // trimmed slots shorter than this are removed instead
+}
+
+// NewPruneSoon creates a PruneSoon policy using the default slot rules
+func NewPruneSoon(lead time.Duration, trim bool) PruneSoon {
+	return PruneSoon{
+		Lead:        lead,
+		Trim:        trim,
+		Granularity: client.DefaultSlotRules.Granularity,
+		MinDuration: client.DefaultSlotRules.MinDuration,
+	}
+}
+
+// Name implements Policy
+func (p PruneSoon) Name() string {
+	return "prune-soon"
+}
+
+// Plan returns the actions needed for the slots, without executing them
+func (p PruneSoon) Plan(now time.Time, slots []client.ReviewSlot) []Action {
+	deadline := now.Add(p.Lead)
+	cut := deadline
+	if p.Granularity > 0 && !cut.Truncate(p.Granularity).Equal(cut) {
+		cut = cut.Truncate(p.Granularity).Add(p.Granularity)
+	}
+
+	var actions []Action
+	for _, s := range slots {
+		if s.Type != client.SlotTypeFree || !s.Start.Before(deadline) || !s.End.After(now) {
+			continue
+		}
+
+		reason := fmt.Sprintf("starts at %s, within %s of now", s.Start.Format("15:04"), p.Lead)
+		if !s.Start.After(now) {
+			reason = fmt.Sprintf("started at %s and is still free", s.Start.Format("15:04"))
+		}
+
+		a := Action{Policy: p.Name(), Kind: ActionRemove, SlotID: s.ID, Before: s.Interval(), Reason: reason}
+		if p.Trim && s.End.Sub(cut) >= p.MinDuration && s.End.Sub(cut) > 0 {
+			a.Kind = ActionTrim
+			a.After = &client.Interval{Start: cut, End: s.End}
+		}
+		actions = append(actions, a)
+	}
+	return actions
+}
+
+// Enforce implements Policy. A trim that the client's slot rules reject
+// falls back to removing the slot.
+func (p PruneSoon) Enforce(ctx context.Context, c *client.Client, now time.Time) ([]Action, error) {
+	// Look back far enough to catch slots that already started
+	slots, _, err := c.GetReviewSlots(ctx, now.Add(-client.DefaultSlotRules.MaxDuration), now.Add(p.Lead))
+	if err != nil {
+		return nil, err
+	}
+
+	actions := apply(ctx, c, p.Plan(now, slots))
+	for i, a := range actions {
+		var vErr *client.ValidationError
+		var cErr *client.ConflictError
+		if a.Kind == ActionTrim && (errors.As(a.Err, &vErr) || errors.As(a.Err, &cErr)) {
+			actions[i].Kind = ActionRemove
+			actions[i].After = nil
+			actions[i].Reason += ", trimmed slot would break the slot rules or conflict with an event"
+			actions[i].Err = c.GuardedRemoveReviewSlot(ctx, a.slot())
+		}
+	}
+	return actions, nil
+}
@@ -0,0 +1,214 @@
+package guard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Period is the span a quota cap applies to
+type Period string
+
+const (
+	// PeriodDay is a calendar day
+	PeriodDay Period = "day"
+	// PeriodWeek is an ISO week, Monday to Sunday
+	PeriodWeek Period = "week"
+)
+
+// Usage is the number of reviews we conduct in one period
+type Usage struct {
+	Period Period
+	Start  time.Time
+	Count  int
+	Cap    int // 0 means no cap
+}
+
+// Full reports whether the cap for the period has been reached
+func (u Usage) Full() bool {
+	return u.Cap > 0 && u.Count >= u.Cap
+}
+
+// Quota caps how many reviews we conduct per day and per week. Once a cap
+// is reached, the remaining free slots in that period are withdrawn and
+// remembered in StatePath; when a cancellation brings the count back under
+// the cap, they are restored.
+type Quota struct {
+	PerDay    int
+	PerWeek   int
+	Login     string         // our login; only bookings where we verify count
+	StatePath string         // where withdrawn slots are remembered
+	Horizon   time.Duration  // how far ahead to enforce
+	Location  *time.Location // zone days and weeks are measured in
+}
+
+// NewQuota creates a quota policy over the platform's booking horizon,
+// with days and weeks in the local zone
+func NewQuota(perDay, perWeek int, login, statePath string) Quota {
+	return Quota{
+		PerDay:    perDay,
+		PerWeek:   perWeek,
+		Login:     login,
+		StatePath: statePath,
+		Horizon:   client.DefaultSlotRules.MaxHorizon,
+		Location:  time.Local,
+	}
+}
+
+// Name implements Policy
+func (q Quota) Name() string {
+	return "quota"
+}
+
+func (q Quota) periodStart(p Period, t time.Time) time.Time {
+	t = t.In(q.Location)
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, q.Location)
+	if p == PeriodWeek {
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	}
+	return day
+}
+
+func (q Quota) cap(p Period) int {
+	if p == PeriodWeek {
+		return q.PerWeek
+	}
+	return q.PerDay
+}
+
+// Usage counts our bookings as verifier per day and per week, for every
+// period from the current one up to now+Horizon, sorted by period start
+func (q Quota) Usage(now time.Time, bookings []client.ReviewBooking) []Usage {
+	counts := make(map[Period]map[time.Time]int)
+	for _, p := range []Period{PeriodDay, PeriodWeek} {
+		counts[p] = make(map[time.Time]int)
+		for t := q.periodStart(p, now); t.Before(now.Add(q.Horizon)); {
+			counts[p][t] = 0
+			if p == PeriodWeek {
+				t = t.AddDate(0, 0, 7)
+			} else {
+				t = t.AddDate(0, 0, 1)
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	for _, b := range bookings {
+		if seen[b.ID] || (q.Login != "" && b.VerifierLogin != q.Login) {
+			continue
+		}
+		seen[b.ID] = true
+		for p := range counts {
+			start := q.periodStart(p, b.Start)
+			if _, ok := counts[p][start]; ok {
+				counts[p][start]++
+			}
+		}
+	}
+
+	var usage []Usage
+	for p, byStart := range counts {
+		for start, n := range byStart {
+			usage = append(usage, Usage{Period: p, Start: start, Count: n, Cap: q.cap(p)})
+		}
+	}
+	sort.Slice(usage, func(a, b int) bool {
+		if usage[a].Period != usage[b].Period {
+			return usage[a].Period == PeriodDay
+		}
+		return usage[a].Start.Before(usage[b].Start)
+	})
+	return usage
+}
+
+// fullReason returns why a slot starting at t may not stay free, or "" if
+// its day and week are both under their caps
+func (q Quota) fullReason(t time.Time, usage []Usage) string {
+	for _, u := range usage {
+		if u.Full() && q.periodStart(u.Period, t).Equal(u.Start) {
+			return fmt.Sprintf("%d of %d reviews booked for the %s of %s", u.Count, u.Cap, u.Period, u.Start.Format("2006-01-02"))
+		}
+	}
+	return ""
+}
+
+// Enforce implements Policy
+func (q Quota) Enforce(ctx context.Context, c *client.Client, now time.Time) ([]Action, error) {
+	from := q.periodStart(PeriodWeek, now)
+	slots, bookings, err := c.GetReviewSlots(ctx, from, now.Add(q.Horizon))
+	if err != nil {
+		return nil, err
+	}
+	usage := q.Usage(now, bookings)
+
+	withdrawn, err := LoadWithdrawn(q.StatePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var actions []Action
+	var kept []Withdrawn
+
+	// Restore slots whose periods dropped back under the caps
+	for _, w := range withdrawn {
+		if w.Policy != q.Name() {
+			kept = append(kept, w)
+			continue
+		}
+		if !w.Slot.Start.After(now) {
+			continue // too late to offer it again
+		}
+		if q.fullReason(w.Slot.Start, usage) != "" {
+			kept = append(kept, w)
+			continue
+		}
+
+		a := Action{Policy: q.Name(), Kind: ActionRestore, SlotID: w.SlotID, Before: w.Slot, After: &w.Slot,
+			Reason: "back under the review quota"}
+		added, err := c.AddReviewSlot(ctx, w.Slot.Start, w.Slot.End)
+		var vErr *client.ValidationError
+		var cErr *client.ConflictError
+		switch {
+		case err == nil:
+			if len(added) > 0 {
+				a.SlotID = added[0].ID
+			}
+		case errors.As(err, &vErr), errors.As(err, &cErr):
+			// The slot can no longer be offered as it was, e.g. it is too
+			// close now or the time is taken; give up on it
+			a.Err = err
+		default:
+			a.Err = err
+			kept = append(kept, w)
+		}
+		actions = append(actions, a)
+	}
+
+	// Withdraw free slots in periods that reached a cap
+	var planned []Action
+	for _, s := range slots {
+		if s.Type != client.SlotTypeFree || !s.Start.After(now) {
+			continue
+		}
+		if reason := q.fullReason(s.Start, usage); reason != "" {
+			planned = append(planned, Action{Policy: q.Name(), Kind: ActionRemove, SlotID: s.ID, Before: s.Interval(), Reason: reason})
+		}
+	}
+	for _, a := range apply(ctx, c, planned) {
+		if a.Err == nil {
+			kept = append(kept, Withdrawn{SlotID: a.SlotID, Slot: a.Before, Policy: q.Name(), Reason: a.Reason, WithdrawnAt: now})
+		}
+		actions = append(actions, a)
+	}
+
+	if len(actions) > 0 {
+		if err := SaveWithdrawn(q.StatePath, kept); err != nil {
+			return actions, err
+		}
+	}
+	return actions, nil
+}
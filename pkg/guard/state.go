@@ -0,0 +1,54 @@
+package guard
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Withdrawn is a free slot a policy removed and may put back later
+type Withdrawn struct {
+	SlotID      string          `json:"slotId"`
+	Slot        client.Interval `json:"slot"`
+	Policy      string          `json:"policy"`
+	Reason      string          `json:"reason"`
+	WithdrawnAt time.Time       `json:"withdrawnAt"`
+}
+
+// LoadWithdrawn reads the withdrawn slots file. A missing file is empty.
+func LoadWithdrawn(path string) ([]Withdrawn, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read withdrawn slots: %w", err)
+	}
+
+	var items []Withdrawn
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("decode withdrawn slots: %w", err)
+	}
+	return items, nil
+}
+
+// SaveWithdrawn writes the withdrawn slots file, creating its directory
+func SaveWithdrawn(path string, items []Withdrawn) error {
+	data, err := json.MarshalIndent(items, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode withdrawn slots: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state dir: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write withdrawn slots: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+// Package journal keeps a local append-only record of the calendar: every
+// slot, booking and event change the watcher observes and every slot
+// mutation the client sends, one JSON object per line.
+package journal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Kind tells what an entry records
+type Kind string
+
+const (
+	// KindChange is a change observed in the calendar
+	KindChange Kind = "change"
+	// KindMutation is a slot mutation we sent
+	KindMutation Kind = "mutation"
+)
+
+// Entry is one line of the journal
+type Entry struct {
+	Time     time.Time              `json:"time"`
+	Kind     Kind                   `json:"kind"`
+	Type     string                 `json:"type"` // change type or mutation op
+	Change   *client.CalendarChange `json:"change,omitempty"`
+	Mutation *client.Mutation       `json:"mutation,omitempty"`
+	Undoes   string                 `json:"undoes,omitempty"` // key of the mutation an undo reverses
+}
+
+// Key identifies an entry, for undo entries to refer to
+func (e Entry) Key() string {
+	key := e.Time.UTC().Format(time.RFC3339Nano) + " " + e.Type
+	if e.Mutation != nil && e.Mutation.SlotID != "" {
+		key += " " + e.Mutation.SlotID
+	}
+	return key
+}
+
+// ChangeEntry records an observed calendar change
+func ChangeEntry(ch client.CalendarChange) Entry {
+	return Entry{Time: ch.DetectedAt, Kind: KindChange, Type: string(ch.Type), Change: &ch}
+}
+
+// MutationEntry records a slot mutation
+func MutationEntry(m client.Mutation) Entry {
+	return Entry{Time: m.At, Kind: KindMutation, Type: string(m.Op), Mutation: &m}
+}
+
+// GoneEntry records a slot found already removed when undoing the mutation
+// with the given key, as a removal that undid it
+func GoneEntry(slot client.ReviewSlot, undoes string, at time.Time) Entry {
+	e := MutationEntry(client.Mutation{Op: client.MutationRemove, SlotID: slot.ID, Before: &slot, At: at})
+	e.Undoes = undoes
+	return e
+}
+
+// Booking returns the booking a change is about, preferring its state after
+// the change, or nil for other entries
+func (e Entry) Booking() *client.ReviewBooking {
+	if e.Change == nil {
+		return nil
+	}
+	if e.Change.BookingAfter != nil {
+		return e.Change.BookingAfter
+	}
+	return e.Change.BookingBefore
+}
+
+// Project returns the project of the booking an entry is about, if any
+func (e Entry) Project() string {
+	if b := e.Booking(); b != nil {
+		return b.ProjectName
+	}
+	return ""
+}
+
+// Verifier returns the verifier of the booking an entry is about, if any
+func (e Entry) Verifier() string {
+	if b := e.Booking(); b != nil {
+		return b.VerifierLogin
+	}
+	return ""
+}
+
+// Bookings replays the booking changes in entries. It returns the last
+// known state of every booking that was still there at its last change,
+// and the bookings whose last change was a cancellation.
+func Bookings(entries []Entry) (seen, cancelled []client.ReviewBooking) {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Time.Before(sorted[b].Time) })
+
+	last := make(map[string]client.ReviewBooking)
+	gone := make(map[string]bool)
+	var order []string
+	for _, e := range sorted {
+		b := e.Booking()
+		if b == nil {
+			continue
+		}
+		if _, ok := last[b.ID]; !ok {
+			order = append(order, b.ID)
+		}
+		last[b.ID] = *b
+		gone[b.ID] = e.Change.Type == client.BookingCancelled
+	}
+
+	for _, id := range order {
+		if gone[id] {
+			cancelled = append(cancelled, last[id])
+		} else {
+			seen = append(seen, last[id])
+		}
+	}
+	return seen, cancelled
+}
+
+// Undoable returns up to n mutations that can still be undone, newest
+// first: mutations the platform accepted that are not undos themselves and
+// have not been undone by a successful undo yet. An add of several slots
+// stays undoable until an undo removed every one of them.
+func Undoable(entries []Entry, n int) []Entry {
+	undos := make(map[string]int)
+	for _, e := range entries {
+		if e.Undoes != "" && e.Mutation != nil && !e.Mutation.Failed() {
+			undos[e.Undoes]++
+		}
+	}
+	undone := func(e Entry) bool {
+		if e.Mutation.Op == client.MutationAdd && len(e.Mutation.After) > 1 {
+			return undos[e.Key()] >= len(e.Mutation.After)
+		}
+		return undos[e.Key()] > 0
+	}
+
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Time.After(sorted[b].Time) })
+
+	var result []Entry
+	for _, e := range sorted {
+		if len(result) == n {
+			break
+		}
+		if e.Kind != KindMutation || e.Mutation == nil || e.Mutation.Failed() || e.Undoes != "" || undone(e) {
+			continue
+		}
+		result = append(result, e)
+	}
+	return result
+}
+
+// Filter selects journal entries. Zero fields match everything.
+type Filter struct {
+	From     time.Time // entries recorded at or after
+	To       time.Time // entries recorded before
+	Kind     Kind
+	Project  string // case-insensitive substring of the project name
+	Verifier string // verifier login, case-insensitive
+}
+
+// Match reports whether an entry passes the filter
+func (f Filter) Match(e Entry) bool {
+	if !f.From.IsZero() && e.Time.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !e.Time.Before(f.To) {
+		return false
+	}
+	if f.Kind != "" && e.Kind != f.Kind {
+		return false
+	}
+	if f.Project != "" && !strings.Contains(strings.ToLower(e.Project()), strings.ToLower(f.Project)) {
+		return false
+	}
+	if f.Verifier != "" && !strings.EqualFold(e.Verifier(), f.Verifier) {
+		return false
+	}
+	return true
+}
+
+// Journal is a JSON Lines file entries are appended to. It is safe for
+// concurrent use within one process.
+type Journal struct {
+	path string
+	mu   sync.Mutex
+}
+
+// New returns the journal kept in path. The file is created on the first
+// append.
+func New(path string) *Journal {
+	return &Journal{path: path}
+}
+
+// Path returns the journal file
+func (j *Journal) Path() string {
+	return j.path
+}
+
+// Append adds entries to the end of the journal, creating its directory
+func (j *Journal) Append(entries ...Entry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			return fmt.Errorf("encode journal entry: %w", err)
+		}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return fmt.Errorf("create journal dir: %w", err)
+	}
+	f, err := os.OpenFile(j.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("open journal: %w", err)
+	}
+	if _, err := f.Write(buf.Bytes()); err != nil {
+		f.Close()
+		return fmt.Errorf("write journal: %w", err)
+	}
+	return f.Close()
+}
+
+// Read returns every entry in the journal. A missing file is an empty
+// journal, and lines that do not decode, such as one cut short by a crash,
+// are skipped; Compact drops them for good.
+func (j *Journal) Read() ([]Entry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.read()
+}
+
+func (j *Journal) read() ([]Entry, error) {
+	f, err := os.Open(j.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open journal: %w", err)
+	}
+	defer f.Close()
+
+	var entries []Entry
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("read journal: %w", err)
+		}
+		var e Entry
+		if len(bytes.TrimSpace(line)) > 0 && json.Unmarshal(line, &e) == nil {
+			entries = append(entries, e)
+		}
+		if err == io.EOF {
+			break
+		}
+	}
+	return entries, nil
+}
+
+// Query returns the entries that pass the filter, oldest first
+func (j *Journal) Query(f Filter) ([]Entry, error) {
+	entries, err := j.Read()
+	if err != nil {
+		return nil, err
+	}
+	var matched []Entry
+	for _, e := range entries {
+		if f.Match(e) {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(a, b int) bool { return matched[a].Time.Before(matched[b].Time) })
+	return matched, nil
+}
+
+// Retention limits how much history Compact keeps. Zero fields keep
+// everything.
+type Retention struct {
+	MaxAge     time.Duration
+	MaxEntries int
+}
+
+// CompactResult reports what Compact dropped
+type CompactResult struct {
+	Kept       int
+	Expired    int // older than MaxAge or beyond MaxEntries
+	Duplicates int
+}
+
+// duplicateWindow is how close in time two identical entries must be for
+// the later one to count as a duplicate, such as the same change seen by
+// two watchers at once
+const duplicateWindow = time.Hour
+
+// Compact rewrites the journal without the entries retention no longer
+// allows and without duplicates. Entries appended by another process while
+// it runs may be lost.
+func (j *Journal) Compact(r Retention, now time.Time) (CompactResult, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var res CompactResult
+	entries, err := j.read()
+	if err != nil {
+		return res, err
+	}
+	sort.SliceStable(entries, func(a, b int) bool { return entries[a].Time.Before(entries[b].Time) })
+
+	lastSeen := make(map[string]time.Time)
+	var kept []Entry
+	for _, e := range entries {
+		if r.MaxAge > 0 && e.Time.Before(now.Add(-r.MaxAge)) {
+			res.Expired++
+			continue
+		}
+		key := dedupKey(e)
+		if t, ok := lastSeen[key]; ok && e.Time.Sub(t) < duplicateWindow {
+			res.Duplicates++
+			continue
+		}
+		lastSeen[key] = e.Time
+		kept = append(kept, e)
+	}
+	if r.MaxEntries > 0 && len(kept) > r.MaxEntries {
+		res.Expired += len(kept) - r.MaxEntries
+		kept = kept[len(kept)-r.MaxEntries:]
+	}
+	res.Kept = len(kept)
+
+	if _, err := os.Stat(j.path); errors.Is(err, fs.ErrNotExist) {
+		return res, nil
+	}
+	return res, j.rewrite(kept)
+}
+
+// dedupKey identifies what an entry records, leaving out when it was
+// recorded
+func dedupKey(e Entry) string {
+	e.Time = time.Time{}
+	if e.Change != nil {
+		ch := *e.Change
+		ch.DetectedAt = time.Time{}
+		e.Change = &ch
+	}
+	if e.Mutation != nil {
+		m := *e.Mutation
+		m.At = time.Time{}
+		e.Mutation = &m
+	}
+	data, _ := json.Marshal(e)
+	return string(data)
+}
+
+// rewrite replaces the journal with entries through a temporary file, so a
+// crash never leaves it half written
+func (j *Journal) rewrite(entries []Entry) error {
+	tmp, err := os.CreateTemp(filepath.Dir(j.path), filepath.Base(j.path)+".*")
+	if err != nil {
+		return fmt.Errorf("create journal: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	enc := json.NewEncoder(w)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			tmp.Close()
+			return fmt.Errorf("encode journal entry: %w", err)
+		}
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("write journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("write journal: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), j.path); err != nil {
+		return fmt.Errorf("replace journal: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package journal
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Undoer reverses journaled mutations. Undoing a removal recreates the slot
+// under a new ID; the undoer remembers it, so older mutations of the old ID
+// can still be undone afterwards.
+type Undoer struct {
+	c   *client.Client
+	ids map[string]string // old slot ID -> ID it was recreated under
+
+	// Gone is called for every slot of an add that is already gone when
+	// the add is undone, so the caller can record it as removed; without
+	// it such an undo fails, as nothing would show the add was undone
+	Gone func(slot client.ReviewSlot)
+}
+
+// NewUndoer creates an undoer that knows the slots recreated by the undos
+// already in entries
+func NewUndoer(c *client.Client, entries []Entry) *Undoer {
+	u := &Undoer{c: c, ids: make(map[string]string)}
+	byKey := make(map[string]Entry, len(entries))
+	for _, e := range entries {
+		if e.Mutation != nil {
+			byKey[e.Key()] = e
+		}
+	}
+	for _, e := range entries {
+		m := e.Mutation
+		if e.Undoes == "" || m == nil || m.Failed() || m.Op != client.MutationAdd || len(m.After) != 1 {
+			continue
+		}
+		if orig, ok := byKey[e.Undoes]; ok && orig.Mutation.Op == client.MutationRemove {
+			u.ids[orig.Mutation.SlotID] = m.After[0].ID
+		}
+	}
+	return u
+}
+
+// SlotID returns the ID a slot has now, following every recreation
+func (u *Undoer) SlotID(id string) string {
+	// A chain is never longer than the map, whatever the journal holds
+	for i := 0; i < len(u.ids); i++ {
+		next, ok := u.ids[id]
+		if !ok {
+			break
+		}
+		id = next
+	}
+	return id
+}
+
+// Undo reverses a recorded mutation from its pre-image. Slots that were
+// booked since are left alone with an error wrapping client.ErrSlotBooked.
+// Slots of an add that are already gone are skipped and reported to Gone,
+// so an add whose undo failed halfway can be undone again; the error of a
+// failed add undo names the slots it did remove.
+func (u *Undoer) Undo(ctx context.Context, m client.Mutation) error {
+	switch m.Op {
+	case client.MutationAdd:
+		if len(m.After) == 0 {
+			return fmt.Errorf("the slots created by this add were not recorded")
+		}
+		var removed []string
+		for _, s := range m.After {
+			s.ID = u.SlotID(s.ID)
+			err := u.c.GuardedRemoveReviewSlot(ctx, s)
+			if errors.Is(err, client.ErrSlotNotFound) && u.Gone != nil {
+				u.Gone(s)
+				continue
+			}
+			if err != nil {
+				if len(removed) > 0 {
+					return fmt.Errorf("removed slot(s) %s, then slot %s failed: %w", strings.Join(removed, ", "), s.ID, err)
+				}
+				return err
+			}
+			removed = append(removed, s.ID)
+		}
+		return nil
+
+	case client.MutationUpdate:
+		if m.Before == nil {
+			return fmt.Errorf("the original times of slot %s were not recorded", m.SlotID)
+		}
+		current := client.ReviewSlot{ID: u.SlotID(m.SlotID), Type: client.SlotTypeFree}
+		switch {
+		case len(m.After) > 0:
+			current.Start, current.End = m.After[0].Start, m.After[0].End
+		case m.Requested != nil:
+			current.Start, current.End = m.Requested.Start, m.Requested.End
+		}
+		_, err := u.c.GuardedUpdateReviewSlot(ctx, current, m.Before.Start, m.Before.End)
+		return err
+
+	case client.MutationRemove:
+		if m.Before == nil {
+			return fmt.Errorf("the times of removed slot %s were not recorded", m.SlotID)
+		}
+		if m.Before.Type != client.SlotTypeFree {
+			return fmt.Errorf("%w: slot %s was %s when it was removed, its review cannot be restored",
+				client.ErrSlotBooked, m.SlotID, m.Before.Type)
+		}
+		slots, err := u.c.AddReviewSlot(ctx, m.Before.Start, m.Before.End)
+		if err == nil && len(slots) == 1 {
+			u.ids[m.SlotID] = slots[0].ID
+		}
+		return err
+	}
+	return fmt.Errorf("unknown mutation %q", m.Op)
+}
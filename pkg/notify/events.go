@@ -0,0 +1,59 @@
+// Package notify turns new and cancelled bookings found by the calendar
+// watcher into events, and dispatches them to hooks: a command that gets
+// the event JSON on stdin, or a webhook signed with HMAC-SHA256.
+package notify
+
+import (
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// EventType is the kind of a booking event
+type EventType string
+
+const (
+	// BookingCreated fires when a booking appears in the calendar
+	BookingCreated EventType = "BookingCreated"
+	// BookingCancelled fires when an upcoming booking disappears
+	BookingCancelled EventType = "BookingCancelled"
+)
+
+// Event is a change in our bookings
+type Event struct {
+	Type          EventType `json:"type"`
+	BookingID     string    `json:"bookingId"`
+	SlotID        string    `json:"slotId"`
+	ProjectName   string    `json:"projectName"`
+	VerifierLogin string    `json:"verifierLogin"`
+	Start         time.Time `json:"start"`
+	End           time.Time `json:"end"`
+	IsOnline      bool      `json:"isOnline"`
+	DetectedAt    time.Time `json:"detectedAt"`
+}
+
+func newEvent(t EventType, b client.ReviewBooking, now time.Time) Event {
+	return Event{
+		Type:          t,
+		BookingID:     b.ID,
+		SlotID:        b.SlotID,
+		ProjectName:   b.ProjectName,
+		VerifierLogin: b.VerifierLogin,
+		Start:         b.Start,
+		End:           b.End,
+		IsOnline:      b.IsOnline,
+		DetectedAt:    now,
+	}
+}
+
+// BookingEvent returns the booking event of a calendar change, and false if
+// the change is not a new or cancelled booking
+func BookingEvent(ch client.CalendarChange) (Event, bool) {
+	switch ch.Type {
+	case client.BookingCreated:
+		return newEvent(BookingCreated, *ch.BookingAfter, ch.DetectedAt), true
+	case client.BookingCancelled:
+		return newEvent(BookingCancelled, *ch.BookingBefore, ch.DetectedAt), true
+	}
+	return Event{}, false
+}
@@ -0,0 +1,162 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// SignatureHeader carries the HMAC-SHA256 of the webhook body as
+// "sha256=<hex>", computed with the hook's secret
+const SignatureHeader = "X-S21-Signature"
+
+// HookType is the kind of a hook
+type HookType string
+
+const (
+	// HookExec runs a command with the event JSON on stdin
+	HookExec HookType = "exec"
+	// HookWebhook POSTs the event JSON to a URL
+	HookWebhook HookType = "webhook"
+)
+
+// Hook is a configured destination for events
+type Hook struct {
+	Type    HookType    `json:"type"`
+	Command string      `json:"command,omitempty"` // exec: run with sh -c
+	URL     string      `json:"url,omitempty"`     // webhook: endpoint
+	Secret  string      `json:"secret,omitempty"`  // webhook: HMAC key, unsigned if empty
+	Events  []EventType `json:"events,omitempty"`  // event types to receive, all if empty
+}
+
+// Wants reports whether the hook subscribes to the event type
+func (h Hook) Wants(t EventType) bool {
+	if len(h.Events) == 0 {
+		return true
+	}
+	for _, e := range h.Events {
+		if e == t {
+			return true
+		}
+	}
+	return false
+}
+
+// LoadHooks reads hooks from a JSON file. A missing file has no hooks.
+func LoadHooks(path string) ([]Hook, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read hooks: %w", err)
+	}
+
+	var hooks []Hook
+	if err := json.Unmarshal(data, &hooks); err != nil {
+		return nil, fmt.Errorf("decode hooks: %w", err)
+	}
+	for i, h := range hooks {
+		switch {
+		case h.Type == HookExec && h.Command == "":
+			return nil, fmt.Errorf("hook %d: exec hook needs a command", i+1)
+		case h.Type == HookWebhook && h.URL == "":
+			return nil, fmt.Errorf("hook %d: webhook needs a url", i+1)
+		case h.Type != HookExec && h.Type != HookWebhook:
+			return nil, fmt.Errorf("hook %d: unknown type %q (use exec or webhook)", i+1, h.Type)
+		}
+	}
+	return hooks, nil
+}
+
+// Sign returns the signature header value for a webhook body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Dispatcher delivers events to hooks
+type Dispatcher struct {
+	Hooks      []Hook
+	HTTPClient *http.Client
+}
+
+// NewDispatcher creates a dispatcher with a default HTTP client
+func NewDispatcher(hooks []Hook) *Dispatcher {
+	return &Dispatcher{
+		Hooks:      hooks,
+		HTTPClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Dispatch delivers an event to every hook that wants it. A failing hook
+// does not stop the others; all failures are joined into the result.
+func (d *Dispatcher) Dispatch(ctx context.Context, e Event) error {
+	body, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+
+	var errs []error
+	for i, h := range d.Hooks {
+		if !h.Wants(e.Type) {
+			continue
+		}
+		var err error
+		switch h.Type {
+		case HookExec:
+			err = d.runExec(ctx, h, e, body)
+		case HookWebhook:
+			err = d.postWebhook(ctx, h, body)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("hook %d (%s): %w", i+1, h.Type, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (d *Dispatcher) runExec(ctx context.Context, h Hook, e Event, body []byte) error {
+	cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+	cmd.Stdin = bytes.NewReader(body)
+	cmd.Env = append(os.Environ(), "S21_EVENT="+string(e.Type))
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
+
+func (d *Dispatcher) postWebhook(ctx context.Context, h Hook, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if h.Secret != "" {
+		req.Header.Set(SignatureHeader, Sign(h.Secret, body))
+	}
+
+	resp, err := d.HTTPClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("do request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook failed with status %d: %s", resp.StatusCode, string(respBody))
+	}
+	return nil
+}
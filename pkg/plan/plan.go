@@ -0,0 +1,249 @@
+// Package plan computes the changes needed to turn the current review slot
+// calendar into a desired set of availability intervals. It never talks to
+// the API: callers fetch the current state, compute a plan, inspect it and
+// only then execute it.
+package plan
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// ActionKind is the type of change an action performs
+type ActionKind string
+
+const (
+	// ActionDelete removes an existing free slot
+	ActionDelete ActionKind = "delete"
+	// ActionResize changes the start or end of an existing free slot
+	ActionResize ActionKind = "resize"
+	// ActionCreate adds a new free slot
+	ActionCreate ActionKind = "create"
+	// ActionKeep leaves a slot as it is
+	ActionKeep ActionKind = "keep"
+)
+
+// kindOrder is the order in which actions must be executed. Deletes go first
+// so that resized and created slots never collide with slots on their way out.
+var kindOrder = map[ActionKind]int{
+	ActionDelete: 0,
+	ActionResize: 1,
+	ActionCreate: 2,
+	ActionKeep:   3,
+}
+
+// Action is a single step of a plan
+type Action struct {
+	Kind   ActionKind       `json:"kind"`
+	SlotID string           `json:"slotId,omitempty"`
+	From   *client.Interval `json:"from,omitempty"` // current state, nil for create
+	To     *client.Interval `json:"to,omitempty"`   // desired state, nil for delete
+	Reason string           `json:"reason"`
+}
+
+// Plan is an ordered list of actions computed against a calendar snapshot
+type Plan struct {
+	CreatedAt   time.Time       `json:"createdAt"`
+	Window      client.Interval `json:"window"`
+	Fingerprint string          `json:"fingerprint"`
+	Actions     []Action        `json:"actions"`
+}
+
+// Counts returns the number of actions of every kind
+func (p *Plan) Counts() map[ActionKind]int {
+	counts := make(map[ActionKind]int)
+	for _, a := range p.Actions {
+		counts[a.Kind]++
+	}
+	return counts
+}
+
+// HasChanges reports whether executing the plan would modify the calendar
+func (p *Plan) HasChanges() bool {
+	for _, a := range p.Actions {
+		if a.Kind != ActionKeep {
+			return true
+		}
+	}
+	return false
+}
+
+// Compute builds a plan that makes the free slots inside window match
+// desired. Booked slots and bookings are never touched and the parts of
+// desired they cover are skipped. Slots that stick out of window are kept
+// as they are, since changing them would affect time outside the plan.
+func Compute(window client.Interval, desired []client.Interval, slots []client.ReviewSlot, bookings []client.ReviewBooking) *Plan {
+	p := &Plan{
+		CreatedAt:   time.Now(),
+		Window:      window,
+		Fingerprint: Fingerprint(window, slots, bookings),
+	}
+
+	var clipped []client.Interval
+	for _, d := range desired {
+		if i, ok := d.Intersect(window); ok {
+			clipped = append(clipped, i)
+		}
+	}
+
+	var busy []client.Interval
+	var free []client.ReviewSlot
+	for _, s := range slots {
+		iv := s.Interval()
+		if !iv.Overlaps(window) {
+			continue
+		}
+		switch {
+		case s.Type != client.SlotTypeFree:
+			busy = append(busy, iv)
+			p.Actions = append(p.Actions, keep(s, "booked slots are never modified"))
+		case !window.Contains(iv):
+			busy = append(busy, iv)
+			p.Actions = append(p.Actions, keep(s, "extends beyond the plan window"))
+		default:
+			free = append(free, s)
+		}
+	}
+	for _, b := range bookings {
+		if b.Interval().Overlaps(window) {
+			busy = append(busy, b.Interval())
+		}
+	}
+
+	sort.Slice(free, func(a, b int) bool { return free[a].Start.Before(free[b].Start) })
+	used := make(map[string]bool)
+
+	for _, d := range client.SubtractIntervals(clipped, busy) {
+		d := d
+		var candidates []client.ReviewSlot
+		for _, s := range free {
+			if !used[s.ID] && s.Interval().Overlaps(d) {
+				candidates = append(candidates, s)
+			}
+		}
+
+		if len(candidates) == 0 {
+			p.Actions = append(p.Actions, Action{
+				Kind:   ActionCreate,
+				To:     &d,
+				Reason: "not covered by an existing free slot",
+			})
+			continue
+		}
+
+		best := candidates[0]
+		bestOverlap := overlap(best.Interval(), d)
+		for _, s := range candidates[1:] {
+			if o := overlap(s.Interval(), d); o > bestOverlap {
+				best, bestOverlap = s, o
+			}
+		}
+
+		for _, s := range candidates {
+			used[s.ID] = true
+			if s.ID == best.ID {
+				continue
+			}
+			from := s.Interval()
+			p.Actions = append(p.Actions, Action{
+				Kind:   ActionDelete,
+				SlotID: s.ID,
+				From:   &from,
+				Reason: fmt.Sprintf("merged into slot %s", best.ID),
+			})
+		}
+
+		from := best.Interval()
+		if from.Equal(d) {
+			p.Actions = append(p.Actions, keep(best, "already matches the desired interval"))
+			continue
+		}
+		p.Actions = append(p.Actions, Action{
+			Kind:   ActionResize,
+			SlotID: best.ID,
+			From:   &from,
+			To:     &d,
+			Reason: "differs from the desired interval",
+		})
+	}
+
+	for _, s := range free {
+		if used[s.ID] {
+			continue
+		}
+		from := s.Interval()
+		p.Actions = append(p.Actions, Action{
+			Kind:   ActionDelete,
+			SlotID: s.ID,
+			From:   &from,
+			Reason: "outside the desired availability",
+		})
+	}
+
+	sort.SliceStable(p.Actions, func(a, b int) bool {
+		ka, kb := kindOrder[p.Actions[a].Kind], kindOrder[p.Actions[b].Kind]
+		if ka != kb {
+			return ka < kb
+		}
+		return p.Actions[a].start().Before(p.Actions[b].start())
+	})
+
+	return p
+}
+
+// Fingerprint returns a digest of the slots and bookings inside window.
+// Two calendars with the same fingerprint are equivalent for planning, which
+// lets a saved plan detect that the calendar changed since it was computed.
+func Fingerprint(window client.Interval, slots []client.ReviewSlot, bookings []client.ReviewBooking) string {
+	var lines []string
+	for _, s := range slots {
+		if s.Interval().Overlaps(window) {
+			lines = append(lines, fmt.Sprintf("slot|%s|%s|%s|%s", s.ID, s.Type,
+				s.Start.UTC().Format(time.RFC3339), s.End.UTC().Format(time.RFC3339)))
+		}
+	}
+	for _, b := range bookings {
+		if b.Interval().Overlaps(window) {
+			lines = append(lines, fmt.Sprintf("booking|%s|%s|%s", b.ID, b.SlotID, b.Status))
+		}
+	}
+	sort.Strings(lines)
+
+	sum := sha256.Sum256([]byte(strings.Join(lines, "\n")))
+	return hex.EncodeToString(sum[:])
+}
+
+func keep(s client.ReviewSlot, reason string) Action {
+	iv := s.Interval()
+	return Action{
+		Kind:   ActionKeep,
+		SlotID: s.ID,
+		From:   &iv,
+		To:     &iv,
+		Reason: reason,
+	}
+}
+
+func overlap(a, b client.Interval) time.Duration {
+	if i, ok := a.Intersect(b); ok {
+		return i.Duration()
+	}
+	return 0
+}
+
+// start returns the time the action is anchored at, used for ordering
+func (a Action) start() time.Time {
+	if a.From != nil {
+		return a.From.Start
+	}
+	if a.To != nil {
+		return a.To.Start
+	}
+	return time.Time{}
+}
@@ -0,0 +1,159 @@
+// Package recur implements recurring availability rules. A rule is a subset
+// of the RFC 5545 RRULE syntax plus a duration and a list of exception dates,
+// and expands into concrete intervals that can be added as review slots.
+package recur
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Frequency is the base repetition period of a rule
+type Frequency string
+
+const (
+	// Daily repeats every INTERVAL days
+	Daily Frequency = "DAILY"
+	// Weekly repeats every INTERVAL weeks on the BYDAY weekdays
+	Weekly Frequency = "WEEKLY"
+)
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// RRule is a parsed recurrence rule. Supported parts are FREQ (DAILY or
+// WEEKLY), INTERVAL, BYDAY, BYHOUR, BYMINUTE, COUNT and UNTIL.
+type RRule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []time.Weekday
+	ByHour   []int
+	ByMinute []int
+	Count    int
+	Until    time.Time // in UTC as written; see UntilIn for UNTIL without a Z
+
+	untilLocal bool // UNTIL had no Z and is a time of the rule's zone
+	untilDate  bool // UNTIL was a DATE and includes that whole day
+}
+
+// UntilIn returns the last moment an occurrence may start at, for a rule
+// expanded in loc. An UNTIL without a trailing Z is a local time in loc, and
+// the DATE form covers the whole of that day.
+func (r *RRule) UntilIn(loc *time.Location) time.Time {
+	if r.Until.IsZero() || !r.untilLocal {
+		return r.Until
+	}
+	u := r.Until
+	until := time.Date(u.Year(), u.Month(), u.Day(), u.Hour(), u.Minute(), u.Second(), 0, loc)
+	if r.untilDate {
+		until = until.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return until
+}
+
+// ParseRRule parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;BYHOUR=18".
+// A leading "RRULE:" prefix is accepted.
+func ParseRRule(s string) (*RRule, error) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	r := &RRule{Interval: 1}
+
+	for _, part := range strings.Split(s, ";") {
+		if part == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rule part %q", part)
+		}
+
+		var err error
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			r.Freq = Frequency(strings.ToUpper(value))
+			if r.Freq != Daily && r.Freq != Weekly {
+				return nil, fmt.Errorf("unsupported FREQ %q (supported: DAILY, WEEKLY)", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "BYDAY":
+			for _, d := range strings.Split(value, ",") {
+				wd, ok := weekdays[strings.ToUpper(d)]
+				if !ok {
+					return nil, fmt.Errorf("invalid BYDAY value %q", d)
+				}
+				r.ByDay = append(r.ByDay, wd)
+			}
+		case "BYHOUR":
+			r.ByHour, err = parseIntList(value, 0, 23)
+		case "BYMINUTE":
+			r.ByMinute, err = parseIntList(value, 0, 59)
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "UNTIL":
+			r.Until, err = parseRRuleTime(value)
+			r.untilLocal = !strings.HasSuffix(value, "Z")
+			r.untilDate = len(value) == len("20060102")
+		default:
+			return nil, fmt.Errorf("unsupported rule part %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", strings.ToUpper(key), err)
+		}
+	}
+
+	if r.Freq == "" {
+		return nil, fmt.Errorf("FREQ is required")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return nil, fmt.Errorf("COUNT and UNTIL cannot be used together")
+	}
+	if r.Freq == Daily && len(r.ByDay) > 0 {
+		return nil, fmt.Errorf("BYDAY is only supported with FREQ=WEEKLY")
+	}
+
+	return r, nil
+}
+
+func parseIntList(s string, min, max int) ([]int, error) {
+	var values []int
+	for _, v := range strings.Split(s, ",") {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, err
+		}
+		if n < min || n > max {
+			return nil, fmt.Errorf("%d out of range %d-%d", n, min, max)
+		}
+		values = append(values, n)
+	}
+	sort.Ints(values)
+	return values, nil
+}
+
+// parseRRuleTime parses the RFC 5545 DATE and DATE-TIME forms. Times
+// without a zone come back in UTC and are moved into the rule's zone by
+// RRule.UntilIn.
+func parseRRuleTime(s string) (time.Time, error) {
+	for _, f := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(f, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to parse %q", s)
+}
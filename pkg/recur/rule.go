@@ -0,0 +1,210 @@
+package recur
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Duration is a time.Duration that is stored as a string such as "1h30m"
+type Duration time.Duration
+
+// MarshalJSON encodes the duration as a string
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON decodes a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(v)
+	return nil
+}
+
+// Rule is a recurring availability rule
+type Rule struct {
+	ID       int       `json:"id"`
+	Name     string    `json:"name,omitempty"`
+	RRule    string    `json:"rrule"`
+	Duration Duration  `json:"duration"`
+	Start    time.Time `json:"start"`             // DTSTART, the first possible occurrence
+	TZ       string    `json:"tz,omitempty"`      // IANA zone BYHOUR is interpreted in, local zone if empty
+	ExDates  []string  `json:"exdates,omitempty"` // YYYY-MM-DD skips a day, YYYY-MM-DD HH:MM one occurrence
+}
+
+// Validate checks that the rule can be expanded
+func (r *Rule) Validate() error {
+	if _, err := ParseRRule(r.RRule); err != nil {
+		return err
+	}
+	if r.Duration <= 0 {
+		return fmt.Errorf("duration must be positive")
+	}
+	loc, err := r.location()
+	if err != nil {
+		return err
+	}
+	if _, _, err := r.exclusions(loc); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (r *Rule) location() (*time.Location, error) {
+	if r.TZ == "" {
+		return time.Local, nil
+	}
+	loc, err := time.LoadLocation(r.TZ)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q: %w", r.TZ, err)
+	}
+	return loc, nil
+}
+
+// exclusions splits ExDates into excluded days and excluded occurrences
+func (r *Rule) exclusions(loc *time.Location) (map[string]bool, map[int64]bool, error) {
+	days := make(map[string]bool)
+	occurrences := make(map[int64]bool)
+	for _, s := range r.ExDates {
+		if t, err := time.ParseInLocation("2006-01-02", s, loc); err == nil {
+			days[t.Format("2006-01-02")] = true
+			continue
+		}
+		t, err := time.ParseInLocation("2006-01-02 15:04", s, loc)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid exdate %q (use YYYY-MM-DD or YYYY-MM-DD HH:MM)", s)
+		}
+		occurrences[t.Unix()] = true
+	}
+	return days, occurrences, nil
+}
+
+// Expand returns the occurrences of the rule that start in [from, to),
+// with excluded dates removed. As in RFC 5545, excluded occurrences still
+// count towards COUNT.
+func (r *Rule) Expand(from, to time.Time) ([]client.Interval, error) {
+	rr, err := ParseRRule(r.RRule)
+	if err != nil {
+		return nil, err
+	}
+	loc, err := r.location()
+	if err != nil {
+		return nil, err
+	}
+	exDays, exOccurrences, err := r.exclusions(loc)
+	if err != nil {
+		return nil, err
+	}
+
+	dtstart := r.Start.In(loc)
+	hours := rr.ByHour
+	if len(hours) == 0 {
+		hours = []int{dtstart.Hour()}
+	}
+	minutes := rr.ByMinute
+	if len(minutes) == 0 {
+		minutes = []int{dtstart.Minute()}
+	}
+	days := make(map[time.Weekday]bool)
+	for _, d := range rr.ByDay {
+		days[d] = true
+	}
+	if len(days) == 0 {
+		days[dtstart.Weekday()] = true
+	}
+
+	until := rr.UntilIn(loc)
+	first := time.Date(dtstart.Year(), dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, loc)
+	var result []client.Interval
+	count := 0
+
+	for day := first; day.Before(to); day = day.AddDate(0, 0, 1) {
+		offset := daysBetween(first, day)
+		switch rr.Freq {
+		case Daily:
+			if offset%rr.Interval != 0 {
+				continue
+			}
+		case Weekly:
+			if !days[day.Weekday()] || daysBetween(weekStart(first), weekStart(day))/7%rr.Interval != 0 {
+				continue
+			}
+		}
+
+		for _, h := range hours {
+			for _, m := range minutes {
+				occ := time.Date(day.Year(), day.Month(), day.Day(), h, m, 0, 0, loc)
+				if occ.Before(dtstart) {
+					continue
+				}
+				if !until.IsZero() && occ.After(until) {
+					return result, nil
+				}
+				count++
+				if rr.Count > 0 && count > rr.Count {
+					return result, nil
+				}
+				if exDays[occ.Format("2006-01-02")] || exOccurrences[occ.Unix()] {
+					continue
+				}
+				if !occ.Before(from) && occ.Before(to) {
+					result = append(result, client.Interval{Start: occ, End: occ.Add(time.Duration(r.Duration))})
+				}
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// ExpandAll expands every rule and merges the resulting intervals
+func ExpandAll(rules []Rule, from, to time.Time) ([]client.Interval, error) {
+	var all []client.Interval
+	for _, r := range rules {
+		intervals, err := r.Expand(from, to)
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: %w", r.ID, err)
+		}
+		all = append(all, intervals...)
+	}
+	return client.MergeIntervals(all), nil
+}
+
+// NextID returns an ID that is not used by any of the rules
+func NextID(rules []Rule) int {
+	id := 0
+	for _, r := range rules {
+		if r.ID > id {
+			id = r.ID
+		}
+	}
+	return id + 1
+}
+
+// SortRules sorts rules by ID
+func SortRules(rules []Rule) {
+	sort.Slice(rules, func(a, b int) bool { return rules[a].ID < rules[b].ID })
+}
+
+// daysBetween counts calendar days from a to b, ignoring DST shifts
+func daysBetween(a, b time.Time) int {
+	ua := time.Date(a.Year(), a.Month(), a.Day(), 0, 0, 0, 0, time.UTC)
+	ub := time.Date(b.Year(), b.Month(), b.Day(), 0, 0, 0, 0, time.UTC)
+	return int(ub.Sub(ua).Hours() / 24)
+}
+
+// weekStart returns the Monday of the week containing t
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
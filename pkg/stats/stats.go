@@ -0,0 +1,184 @@
+// Package stats summarizes review bookings: how many reviews per project,
+// per week and per hour of day, how much time they take, who is reviewed
+// most often and how many bookings get cancelled.
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// Bucket counts the reviews that share a key, such as a project or a week
+type Bucket struct {
+	Key     string `json:"key"`
+	Reviews int    `json:"reviews"`
+	Minutes int    `json:"minutes"`
+}
+
+// Report is the result of Analyze
+type Report struct {
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	Reviews          int       `json:"reviews"` // held or upcoming, not cancelled
+	Cancelled        int       `json:"cancelled"`
+	CancellationRate float64   `json:"cancellationRate"` // cancelled share of all bookings
+	TotalMinutes     int       `json:"totalMinutes"`
+	ByProject        []Bucket  `json:"byProject"`    // most reviews first
+	ByWeek           []Bucket  `json:"byWeek"`       // ISO weeks in order, such as 2025-W03
+	ByHour           []Bucket  `json:"byHour"`       // hours of day with reviews, such as 14:00
+	TopReviewees     []Bucket  `json:"topReviewees"` // most reviews first
+}
+
+// TotalTime returns the time spent in reviews
+func (r *Report) TotalTime() time.Duration {
+	return time.Duration(r.TotalMinutes) * time.Minute
+}
+
+// Options tune Analyze
+type Options struct {
+	Roles    []client.Role  // bookings to count, every role if empty
+	Location *time.Location // zone weeks and hours are taken in, local if nil
+	Top      int            // length of TopReviewees, 10 if zero
+}
+
+// defaultTop is the number of reviewees reported unless Options.Top is set
+const defaultTop = 10
+
+// Analyze reports on the bookings that start within [from, to). cancelled
+// lists bookings known to have been cancelled, such as from the local
+// journal; a booking found in both lists still took place. Bookings are
+// counted once per ID.
+func Analyze(bookings, cancelled []client.ReviewBooking, from, to time.Time, opts Options) *Report {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	top := opts.Top
+	if top <= 0 {
+		top = defaultTop
+	}
+
+	r := &Report{From: from, To: to}
+	held := make(map[string]bool)
+	byProject := make(map[string]*Bucket)
+	byWeek := make(map[string]*Bucket)
+	byHour := make(map[string]*Bucket)
+	byReviewee := make(map[string]*Bucket)
+
+	for _, b := range bookings {
+		if held[b.ID] || !counted(b, from, to, opts.Roles) {
+			continue
+		}
+		held[b.ID] = true
+
+		minutes := int(b.End.Sub(b.Start) / time.Minute)
+		r.Reviews++
+		r.TotalMinutes += minutes
+
+		start := b.Start.In(loc)
+		year, week := start.ISOWeek()
+		add(byProject, b.ProjectName, minutes)
+		add(byWeek, fmt.Sprintf("%04d-W%02d", year, week), minutes)
+		add(byHour, fmt.Sprintf("%02d:00", start.Hour()), minutes)
+		for _, login := range reviewees(b) {
+			add(byReviewee, login, minutes)
+		}
+	}
+
+	seenCancelled := make(map[string]bool)
+	for _, b := range cancelled {
+		if held[b.ID] || seenCancelled[b.ID] || !counted(b, from, to, opts.Roles) {
+			continue
+		}
+		seenCancelled[b.ID] = true
+		r.Cancelled++
+	}
+	if total := r.Reviews + r.Cancelled; total > 0 {
+		r.CancellationRate = float64(r.Cancelled) / float64(total)
+	}
+
+	r.ByProject = byCount(byProject)
+	r.ByWeek = byKey(byWeek)
+	r.ByHour = byKey(byHour)
+	r.TopReviewees = byCount(byReviewee)
+	if len(r.TopReviewees) > top {
+		r.TopReviewees = r.TopReviewees[:top]
+	}
+	return r
+}
+
+// counted reports whether a booking falls into the analyzed range and roles
+func counted(b client.ReviewBooking, from, to time.Time, roles []client.Role) bool {
+	if b.Start.Before(from) || !b.Start.Before(to) {
+		return false
+	}
+	if len(roles) == 0 {
+		return true
+	}
+	for _, role := range roles {
+		if b.Role == role {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewees returns the logins whose work a booking reviews: the students
+// under review, or the project team if the platform did not list them
+func reviewees(b client.ReviewBooking) []string {
+	people := b.Reviewees
+	if len(people) == 0 {
+		people = b.Team
+	}
+	var logins []string
+	for _, p := range people {
+		if p.Login != "" {
+			logins = append(logins, p.Login)
+		}
+	}
+	return logins
+}
+
+func add(buckets map[string]*Bucket, key string, minutes int) {
+	if key == "" {
+		key = "unknown"
+	}
+	b, ok := buckets[key]
+	if !ok {
+		b = &Bucket{Key: key}
+		buckets[key] = b
+	}
+	b.Reviews++
+	b.Minutes += minutes
+}
+
+// byCount returns the buckets with the most reviews first, ties by key
+func byCount(buckets map[string]*Bucket) []Bucket {
+	list := flatten(buckets)
+	sort.Slice(list, func(a, b int) bool {
+		if list[a].Reviews != list[b].Reviews {
+			return list[a].Reviews > list[b].Reviews
+		}
+		return strings.ToLower(list[a].Key) < strings.ToLower(list[b].Key)
+	})
+	return list
+}
+
+// byKey returns the buckets in key order
+func byKey(buckets map[string]*Bucket) []Bucket {
+	list := flatten(buckets)
+	sort.Slice(list, func(a, b int) bool { return list[a].Key < list[b].Key })
+	return list
+}
+
+func flatten(buckets map[string]*Bucket) []Bucket {
+	list := make([]Bucket, 0, len(buckets))
+	for _, b := range buckets {
+		list = append(list, *b)
+	}
+	return list
+}
@@ -0,0 +1,105 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestMockClient_BatchRemoveRefusesBookedSlots(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+		"slot-2": {start: base.Add(2 * time.Hour), end: base.Add(3 * time.Hour), booked: true},
+	})
+	c := newSlotClient(t, ss)
+
+	results := c.RemoveReviewSlots(context.Background(), []string{"slot-1", "slot-2", "slot-gone"}, 2, false)
+	if results[0].Err != nil {
+		t.Errorf("Remove of free slot-1 error = %v", results[0].Err)
+	}
+	if !errors.Is(results[1].Err, client.ErrSlotBooked) {
+		t.Errorf("Remove of booked slot-2 error = %v, want ErrSlotBooked", results[1].Err)
+	}
+	if !errors.Is(results[2].Err, client.ErrSlotNotFound) {
+		t.Errorf("Remove of unknown slot error = %v, want ErrSlotNotFound", results[2].Err)
+	}
+	if _, ok := ss.slot("slot-1"); ok {
+		t.Error("Free slot-1 was not removed")
+	}
+	if _, ok := ss.slot("slot-2"); !ok {
+		t.Error("Booked slot-2 was removed")
+	}
+
+	results = c.RemoveReviewSlots(context.Background(), []string{"slot-2"}, 1, true)
+	if results[0].Err != nil {
+		t.Errorf("Forced remove of booked slot-2 error = %v", results[0].Err)
+	}
+	if _, ok := ss.slot("slot-2"); ok {
+		t.Error("Forced remove left booked slot-2 in place")
+	}
+}
+
+func TestMockClient_BatchUpdateRefusesBookedSlots(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+		"slot-2": {start: base.Add(2 * time.Hour), end: base.Add(3 * time.Hour), booked: true},
+	})
+	c := newSlotClient(t, ss)
+
+	later := base.Add(5 * time.Hour)
+	results := c.UpdateReviewSlots(context.Background(), []client.SlotUpdate{
+		{SlotID: "slot-1", Interval: client.Interval{Start: later, End: later.Add(time.Hour)}},
+		{SlotID: "slot-2", Interval: client.Interval{Start: later.Add(2 * time.Hour), End: later.Add(3 * time.Hour)}},
+	}, 2, false)
+	if results[0].Err != nil || len(results[0].Slots) != 1 {
+		t.Errorf("Update of free slot-1 = %+v, want the moved slot", results[0])
+	}
+	if !errors.Is(results[1].Err, client.ErrSlotBooked) {
+		t.Errorf("Update of booked slot-2 error = %v, want ErrSlotBooked", results[1].Err)
+	}
+	if s2, _ := ss.slot("slot-2"); !s2.start.Equal(base.Add(2 * time.Hour)) {
+		t.Errorf("Booked slot-2 moved to %s", s2.start)
+	}
+}
+
+func TestMockClient_ClearPlanIsAppliedSeparately(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+		"slot-2": {start: base.Add(2 * time.Hour), end: base.Add(3 * time.Hour)},
+		"slot-3": {start: base.Add(5 * time.Hour), end: base.Add(7 * time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	ctx := context.Background()
+
+	plan, err := c.PlanClear(ctx, base, base.Add(6*time.Hour))
+	if err != nil {
+		t.Fatalf("PlanClear() error = %v", err)
+	}
+	if len(plan.Targets) != 2 || plan.Targets[0].ID != "slot-1" || len(plan.Skipped) != 1 {
+		t.Fatalf("PlanClear() = %+v, want slot-1 and slot-2 targeted, slot-3 skipped", plan)
+	}
+	if _, ok := ss.slot("slot-1"); !ok {
+		t.Fatal("PlanClear() removed a slot")
+	}
+
+	ss.book("slot-2")
+	result := c.ApplyClear(ctx, plan)
+	if len(result.Removed) != 1 || result.Removed[0].ID != "slot-1" {
+		t.Errorf("Removed = %+v, want only slot-1", result.Removed)
+	}
+	if len(result.Failed) != 1 || !errors.Is(result.Failed[0].Err, client.ErrSlotBooked) {
+		t.Errorf("Failed = %+v, want slot-2 refused as booked", result.Failed)
+	}
+	if _, ok := ss.slot("slot-2"); !ok {
+		t.Error("slot booked after planning was removed")
+	}
+}
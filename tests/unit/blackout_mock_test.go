@@ -0,0 +1,199 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/blackout"
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestBlackout_Blocks(t *testing.T) {
+	cfg := &blackout.Config{
+		TZ: "UTC",
+		Quiet: []blackout.QuietHours{
+			{From: "22:00", To: "08:00", Reason: "night"},
+			{Days: []string{"WE"}, From: "12:00", To: "13:00"},
+		},
+		Ranges: []blackout.Range{{Start: "2025-01-16", End: "2025-01-17", Reason: "vacation"}},
+	}
+	// Tuesday 12:00 to Thursday 00:00
+	from := time.Date(2025, 1, 14, 12, 0, 0, 0, time.UTC)
+	blocks, err := cfg.Blocks(from, from.Add(36*time.Hour))
+	if err != nil {
+		t.Fatalf("Blocks() error = %v", err)
+	}
+
+	at := func(day, h int) time.Time { return time.Date(2025, 1, day, h, 0, 0, 0, time.UTC) }
+	want := []blackout.Block{
+		{Interval: client.Interval{Start: at(14, 22), End: at(15, 8)}, Reason: "night"},
+		{Interval: client.Interval{Start: at(15, 12), End: at(15, 13)}, Reason: "quiet hours 12:00-13:00"},
+		{Interval: client.Interval{Start: at(15, 22), End: at(16, 0)}, Reason: "night"}, // clipped to the window
+	}
+	if len(blocks) != len(want) {
+		t.Fatalf("Blocks() = %v, want %v", blocks, want)
+	}
+	for i := range want {
+		if !blocks[i].Interval.Equal(want[i].Interval) || blocks[i].Reason != want[i].Reason {
+			t.Errorf("Block %d = %v (%s), want %v (%s)", i, blocks[i].Interval, blocks[i].Reason, want[i].Interval, want[i].Reason)
+		}
+	}
+}
+
+func TestBlackout_QuietHoursKeepWallClockOverDST(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	cfg := &blackout.Config{TZ: "Europe/Berlin", Quiet: []blackout.QuietHours{{From: "09:00", To: "10:00"}}}
+
+	// Clocks go forward on March 30 and back on October 26
+	for _, day := range []int{30, 26} {
+		month := time.March
+		if day == 26 {
+			month = time.October
+		}
+		from := time.Date(2025, month, day, 0, 0, 0, 0, berlin)
+		blocks, err := cfg.Blocks(from, from.AddDate(0, 0, 1))
+		if err != nil {
+			t.Fatalf("Blocks() error = %v", err)
+		}
+		want := client.Interval{Start: time.Date(2025, month, day, 9, 0, 0, 0, berlin), End: time.Date(2025, month, day, 10, 0, 0, 0, berlin)}
+		if len(blocks) != 1 || !blocks[0].Interval.Equal(want) {
+			t.Errorf("Blocks() on %s = %v, want %v", from.Format("2006-01-02"), blocks, want)
+		}
+	}
+}
+
+func TestBlackout_ClipAndApply(t *testing.T) {
+	cfg := &blackout.Config{TZ: "UTC", Quiet: []blackout.QuietHours{{From: "22:00", To: "08:00"}}}
+	start := time.Date(2025, 1, 15, 20, 0, 0, 0, time.UTC)
+	end := start.Add(3*time.Hour + 30*time.Minute)
+
+	res, err := cfg.Apply(blackout.ModeClip, start, end)
+	if err != nil {
+		t.Fatalf("Apply(clip) error = %v", err)
+	}
+	if len(res.Allowed) != 1 || !res.Allowed[0].Equal(client.Interval{Start: start, End: start.Add(2 * time.Hour)}) {
+		t.Errorf("Allowed = %v, want 20:00-22:00", res.Allowed)
+	}
+	if len(res.Clipped) != 1 || !res.Clipped[0].End.Equal(end) {
+		t.Errorf("Clipped = %v, want 22:00-23:30", res.Clipped)
+	}
+
+	var bErr *blackout.Error
+	if _, err := cfg.Apply(blackout.ModeReject, start, end); !errors.As(err, &bErr) {
+		t.Errorf("Apply(reject) error = %v, want *blackout.Error", err)
+	}
+	night := start.Add(3 * time.Hour)
+	if _, err := cfg.Apply(blackout.ModeClip, night, night.Add(time.Hour)); !errors.As(err, &bErr) {
+		t.Errorf("Apply(clip) of a slot fully in the night error = %v, want *blackout.Error", err)
+	}
+	if res, err := cfg.Apply(blackout.ModeReject, start, start.Add(time.Hour)); err != nil || res.Changed() {
+		t.Errorf("Apply(reject) outside blackouts = %+v, %v, want unchanged", res, err)
+	}
+}
+
+func TestBlackout_LoadICSSkipsUnsupportedEvents(t *testing.T) {
+	ics := strings.Join([]string{
+		"BEGIN:VCALENDAR",
+		"BEGIN:VEVENT",
+		"DTSTART:20250115T100000Z",
+		"DTEND:20250115T110000Z",
+		"SUMMARY:Dentist appoint",
+		" ment",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20250115T120000Z",
+		"DTEND:20250115T130000Z",
+		"TRANSP:TRANSPARENT",
+		"SUMMARY:Free lunch",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20250113T180000Z",
+		"DTEND:20250113T190000Z",
+		"RRULE:FREQ=WEEKLY;BYDAY=MO,WE",
+		"SUMMARY:Gym",
+		"END:VEVENT",
+		"BEGIN:VEVENT",
+		"DTSTART:20250101T090000Z",
+		"DTEND:20250101T100000Z",
+		"RRULE:FREQ=MONTHLY;BYMONTHDAY=15",
+		"SUMMARY:Rent",
+		"END:VEVENT",
+		"END:VCALENDAR",
+	}, "\r\n")
+	path := filepath.Join(t.TempDir(), "busy.ics")
+	if err := os.WriteFile(path, []byte(ics), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	from := time.Date(2025, 1, 15, 0, 0, 0, 0, time.UTC)
+	blocks, skipped, err := blackout.LoadICS(path, from, from.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("LoadICS() error = %v", err)
+	}
+	if len(skipped) != 1 || !strings.Contains(skipped[0].Error(), "Rent") {
+		t.Errorf("Skipped = %v, want the monthly event", skipped)
+	}
+	if len(blocks) != 2 {
+		t.Fatalf("LoadICS() = %v, want the dentist and the Wednesday gym", blocks)
+	}
+	if blocks[0].Reason != "busy: Dentist appointment" || blocks[0].Start.Hour() != 10 {
+		t.Errorf("Block 0 = %v (%s), want the unfolded dentist event at 10:00", blocks[0].Interval, blocks[0].Reason)
+	}
+	if blocks[1].Reason != "busy: Gym" || !blocks[1].Start.Equal(from.Add(18*time.Hour)) {
+		t.Errorf("Block 1 = %v (%s), want the gym at 18:00", blocks[1].Interval, blocks[1].Reason)
+	}
+
+	// The skipped event reaches the config's Warn instead of failing Blocks
+	var warned []error
+	cfg := &blackout.Config{TZ: "UTC", ICS: []string{path}, Warn: func(err error) { warned = append(warned, err) }}
+	if _, err := cfg.Blocks(from, from.Add(24*time.Hour)); err != nil || len(warned) != 1 {
+		t.Errorf("Blocks() error = %v, warnings = %v, want one warning", err, warned)
+	}
+}
+
+func TestMockClient_SlotFilterAppliesBlackouts(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base.Add(6 * time.Hour), end: base.Add(7 * time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	cfg := &blackout.Config{TZ: "UTC", Ranges: []blackout.Range{{
+		Start: base.Add(time.Hour).Format("2006-01-02 15:04"),
+		End:   base.Add(2 * time.Hour).Format("2006-01-02 15:04"),
+	}}}
+	c.SetSlotFilter(cfg.Filter(blackout.ModeClip))
+	ctx := context.Background()
+
+	added, err := c.AddReviewSlot(ctx, base, base.Add(4*time.Hour))
+	if err != nil {
+		t.Fatalf("AddReviewSlot() error = %v", err)
+	}
+	if len(added) != 2 || !added[0].End.Equal(base.Add(time.Hour)) || !added[1].Start.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("AddReviewSlot() = %+v, want the parts before and after the blackout", added)
+	}
+
+	var bErr *blackout.Error
+	_, err = c.UpdateReviewSlot(ctx, "slot-1", base.Add(90*time.Minute), base.Add(150*time.Minute))
+	if !errors.Is(err, client.ErrSlotFiltered) || !errors.As(err, &bErr) {
+		t.Errorf("UpdateReviewSlot() into the blackout error = %v, want ErrSlotFiltered and *blackout.Error", err)
+	}
+	if s1, _ := ss.slot("slot-1"); !s1.start.Equal(base.Add(6 * time.Hour)) {
+		t.Errorf("slot-1 moved to %s", s1.start)
+	}
+
+	res, err := c.EnsureReviewSlot(ctx, base, base.Add(4*time.Hour))
+	if err != nil || res.Changed() {
+		t.Errorf("EnsureReviewSlot() over the same interval = %+v, %v, want nothing to do", res, err)
+	}
+}
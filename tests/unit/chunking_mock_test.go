@@ -0,0 +1,273 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+// calendarFixture is a calendar the mock server answers from, returning the
+// events and slots that overlap the requested range
+var calendarFixture = []map[string]interface{}{
+	{
+		"id":        "event-review",
+		"start":     "2025-03-01T10:00:00Z",
+		"end":       "2025-03-09T12:00:00Z",
+		"eventType": "Я проверяю",
+		"eventCode": "student_check",
+		"eventSlots": []map[string]interface{}{
+			{"id": "slot-1", "type": "FREE_TIME", "start": "2025-03-01T10:00:00Z", "end": "2025-03-01T11:00:00Z"},
+			{"id": "slot-2", "type": "BOOKED_TIME", "start": "2025-03-04T15:00:00Z", "end": "2025-03-04T15:30:00Z"},
+			{"id": "slot-3", "type": "FREE_TIME", "start": "2025-03-09T11:00:00Z", "end": "2025-03-09T12:00:00Z"},
+		},
+		"bookings": []map[string]interface{}{
+			{
+				"id":            "booking-1",
+				"eventSlot":     map[string]interface{}{"id": "slot-2", "start": "2025-03-04T15:00:00Z", "end": "2025-03-04T15:30:00Z"},
+				"task":          map[string]interface{}{"goalName": "libft"},
+				"verifierUser":  map[string]interface{}{"login": "me"},
+				"bookingStatus": "APPROVED",
+			},
+		},
+	},
+	{
+		"id":        "event-exam",
+		"start":     "2025-03-05T09:00:00Z",
+		"end":       "2025-03-05T13:00:00Z",
+		"eventType": "Экзамен",
+		"eventCode": "exam",
+		"exam":      map[string]interface{}{"name": "Exam 01"},
+	},
+	{
+		"id":        "event-late",
+		"start":     "2025-03-20T18:00:00Z",
+		"end":       "2025-03-20T19:00:00Z",
+		"eventType": "Я проверяю",
+		"eventCode": "student_check",
+		"eventSlots": []map[string]interface{}{
+			{"id": "slot-4", "type": "FREE_TIME", "start": "2025-03-20T18:00:00Z", "end": "2025-03-20T19:00:00Z"},
+		},
+	},
+}
+
+func overlaps(start, end string, from, to time.Time) bool {
+	s, _ := time.Parse(time.RFC3339, start)
+	e, _ := time.Parse(time.RFC3339, end)
+	return s.Before(to) && e.After(from)
+}
+
+// calendarServer serves calendarFixture and counts the requests it gets
+type calendarServer struct {
+	requests int32
+	inFlight int32
+	peak     int32
+	fail     bool
+}
+
+// count returns how many requests the server got so far
+func (cs *calendarServer) count() int32 {
+	return atomic.LoadInt32(&cs.requests)
+}
+
+func (cs *calendarServer) handle(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&cs.requests, 1)
+	n := atomic.AddInt32(&cs.inFlight, 1)
+	defer atomic.AddInt32(&cs.inFlight, -1)
+	for {
+		p := atomic.LoadInt32(&cs.peak)
+		if n <= p || atomic.CompareAndSwapInt32(&cs.peak, p, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+
+	if cs.fail {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	var req client.GraphQLRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	from, _ := time.Parse(time.RFC3339, req.Variables["from"].(string))
+	to, _ := time.Parse(time.RFC3339, req.Variables["to"].(string))
+
+	var events []map[string]interface{}
+	for _, e := range calendarFixture {
+		if !overlaps(e["start"].(string), e["end"].(string), from, to) {
+			continue
+		}
+		clipped := make(map[string]interface{}, len(e))
+		for k, v := range e {
+			clipped[k] = v
+		}
+		if slots, ok := e["eventSlots"].([]map[string]interface{}); ok {
+			var inRange []map[string]interface{}
+			for _, s := range slots {
+				if overlaps(s["start"].(string), s["end"].(string), from, to) {
+					inRange = append(inRange, s)
+				}
+			}
+			clipped["eventSlots"] = inRange
+		}
+		if bookings, ok := e["bookings"].([]map[string]interface{}); ok {
+			var inRange []map[string]interface{}
+			for _, b := range bookings {
+				slot := b["eventSlot"].(map[string]interface{})
+				if overlaps(slot["start"].(string), slot["end"].(string), from, to) {
+					inRange = append(inRange, b)
+				}
+			}
+			clipped["bookings"] = inRange
+		}
+		events = append(events, clipped)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"data": map[string]interface{}{
+			"calendarEventS21": map[string]interface{}{"getMyCalendarEvents": events},
+		},
+	})
+}
+
+func newCalendarClient(t *testing.T, cs *calendarServer, opts ...client.ClientOption) *client.Client {
+	t.Helper()
+	tokenResp := &client.TokenResponse{AccessToken: "mock-token", TokenType: "Bearer", ExpiresIn: 3600}
+
+	authServer := mockAuthServer(tokenResp)
+	t.Cleanup(authServer.Close)
+	graphqlServer := mockGraphQLServer(cs.handle)
+	t.Cleanup(graphqlServer.Close)
+
+	opts = append([]client.ClientOption{
+		client.WithAuthURL(authServer.URL),
+		client.WithBaseURL(graphqlServer.URL),
+	}, opts...)
+	c := client.NewClient(&client.AuthConfig{Login: "test@example.com", Password: "testpass"}, opts...)
+	c.SetToken(tokenResp, time.Now().Add(time.Hour))
+	return c
+}
+
+func TestMockClient_CalendarChunkingIsTransparent(t *testing.T) {
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2025, 3, 31, 0, 0, 0, 0, time.UTC)
+
+	whole := &calendarServer{}
+	wantSlots, wantBookings, err := newCalendarClient(t, whole, client.WithCalendarChunking(0, 1)).GetReviewSlots(context.Background(), from, to)
+	if err != nil {
+		t.Fatalf("GetReviewSlots() without chunking failed = %v", err)
+	}
+	if whole.count() != 1 {
+		t.Errorf("Without chunking got %d requests, want 1", whole.count())
+	}
+	if len(wantSlots) != 4 || len(wantBookings) != 1 {
+		t.Fatalf("Without chunking got %d slots and %d bookings, want 4 and 1", len(wantSlots), len(wantBookings))
+	}
+
+	for _, size := range []time.Duration{time.Hour, 6 * time.Hour, 24 * time.Hour, 3 * 24 * time.Hour, 10 * 24 * time.Hour} {
+		cs := &calendarServer{}
+		c := newCalendarClient(t, cs, client.WithCalendarChunking(size, 3))
+
+		slots, bookings, err := c.GetReviewSlots(context.Background(), from, to)
+		if err != nil {
+			t.Fatalf("GetReviewSlots() with %s windows failed = %v", size, err)
+		}
+		if !reflect.DeepEqual(slots, wantSlots) {
+			t.Errorf("Slots with %s windows = %+v, want %+v", size, slots, wantSlots)
+		}
+		if !reflect.DeepEqual(bookings, wantBookings) {
+			t.Errorf("Bookings with %s windows = %+v, want %+v", size, bookings, wantBookings)
+		}
+
+		wantRequests := int32((to.Sub(from) + size - 1) / size)
+		if cs.count() != wantRequests {
+			t.Errorf("With %s windows got %d requests, want %d", size, cs.count(), wantRequests)
+		}
+	}
+}
+
+func TestMockClient_CalendarChunkingDeduplicatesEvents(t *testing.T) {
+	cs := &calendarServer{}
+	c := newCalendarClient(t, cs, client.WithCalendarChunking(24*time.Hour, 4))
+
+	resp, err := c.GetCalendarEvents(context.Background(), "2025-03-01T00:00:00Z", "2025-03-31T00:00:00Z")
+	if err != nil {
+		t.Fatalf("GetCalendarEvents() failed = %v", err)
+	}
+
+	events := resp.CalendarEventS21.GetMyCalendarEvents
+	var ids []string
+	for _, e := range events {
+		ids = append(ids, e.ID)
+	}
+	if want := []string{"event-review", "event-exam", "event-late"}; !reflect.DeepEqual(ids, want) {
+		t.Fatalf("Event IDs = %v, want %v", ids, want)
+	}
+
+	// The review event spans nine daily windows; its slots come from three
+	var slotIDs []string
+	for _, s := range events[0].EventSlots {
+		slotIDs = append(slotIDs, s.ID)
+	}
+	if want := []string{"slot-1", "slot-2", "slot-3"}; !reflect.DeepEqual(slotIDs, want) {
+		t.Errorf("Slot IDs = %v, want %v", slotIDs, want)
+	}
+	if len(events[0].Bookings) != 1 {
+		t.Errorf("Got %d bookings, want 1", len(events[0].Bookings))
+	}
+}
+
+func TestMockClient_CalendarChunkingBoundsConcurrency(t *testing.T) {
+	cs := &calendarServer{}
+	c := newCalendarClient(t, cs, client.WithCalendarChunking(24*time.Hour, 2))
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, _, err := c.GetReviewSlots(context.Background(), from, from.AddDate(0, 0, 10)); err != nil {
+		t.Fatalf("GetReviewSlots() failed = %v", err)
+	}
+
+	if cs.count() != 10 {
+		t.Errorf("Got %d requests, want 10", cs.count())
+	}
+	if atomic.LoadInt32(&cs.peak) > 2 {
+		t.Errorf("Peak concurrency = %d, want at most 2", atomic.LoadInt32(&cs.peak))
+	}
+}
+
+func TestMockClient_CalendarChunkingFails(t *testing.T) {
+	cs := &calendarServer{fail: true}
+	c := newCalendarClient(t, cs, client.WithCalendarChunking(24*time.Hour, 2))
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, _, err := c.GetReviewSlots(context.Background(), from, from.AddDate(0, 0, 10)); err == nil {
+		t.Fatal("GetReviewSlots() succeeded, want an error")
+	}
+	if cs.count() >= 10 {
+		t.Errorf("Got %d requests, want the first error to stop the rest", cs.count())
+	}
+}
+
+func TestMockClient_CalendarChunkingCancel(t *testing.T) {
+	cs := &calendarServer{}
+	c := newCalendarClient(t, cs, client.WithCalendarChunking(time.Hour, 1))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	from := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	if _, _, err := c.GetReviewSlots(ctx, from, from.AddDate(0, 0, 10)); err == nil {
+		t.Fatal("GetReviewSlots() succeeded, want a context error")
+	}
+	if cs.count() >= 240 {
+		t.Errorf("Got %d requests, want cancellation to stop the rest", cs.count())
+	}
+}
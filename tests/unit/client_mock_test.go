@@ -11,7 +11,7 @@ import (
 	"testing"
 	"time"
 
-	"github.com/arseni/s21-api-client/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/client"
 )
 
 // mockAuthServer creates a mock auth server
@@ -41,6 +41,12 @@ func mockAuthServer(tokenResp *client.TokenResponse) *httptest.Server {
 // mockGraphQLServer creates a mock GraphQL server
 func mockGraphQLServer(handler func(http.ResponseWriter, *http.Request)) *httptest.Server {
 	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == client.ContextInfoPath {
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(client.ContextInfoResponse{Success: true})
+			return
+		}
+
 		if r.URL.Path == "/services/graphql" {
 			// Check auth header
 			auth := r.Header.Get("Authorization")
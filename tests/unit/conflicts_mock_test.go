@@ -0,0 +1,105 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestParseEventKind(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    client.EventKind
+		wantErr bool
+	}{
+		{"exam", client.EventExam, false},
+		{"Activity", client.EventActivity, false},
+		{"penalty", client.EventPenalty, false},
+		{"review", client.EventReview, false},
+		{"reviewed", client.EventReviewed, false},
+		{"slot", client.EventSlot, false},
+		{"OTHER", client.EventOther, false},
+		{"exams", "", true},
+		{"", "", true},
+	}
+	for _, tt := range tests {
+		got, err := client.ParseEventKind(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseEventKind(%q) = %q, %v; want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestBusyEvents_ReviewRoles(t *testing.T) {
+	var events []client.CalendarEvent
+	err := json.Unmarshal([]byte(`[{
+		"id": "event-review",
+		"eventCode": "student_check",
+		"eventSlots": [
+			{"id": "free", "type": "FREE_TIME", "start": "2025-03-03T10:00:00Z", "end": "2025-03-03T11:00:00Z"},
+			{"id": "checked", "type": "BOOKED_TIME", "start": "2025-03-03T11:00:00Z", "end": "2025-03-03T12:00:00Z",
+				"event": {"eventUserRole": "VERIFIABLE"}},
+			{"id": "checking", "type": "BOOKED_TIME", "start": "2025-03-03T12:00:00Z", "end": "2025-03-03T13:00:00Z",
+				"event": {"eventUserRole": "VERIFIER"}}
+		],
+		"bookings": [
+			{"id": "booking-ours", "eventSlot": {"id": "slot-ours", "start": "2025-03-03T14:00:00Z", "end": "2025-03-03T15:00:00Z",
+				"event": {"eventUserRole": "VERIFIABLE"}}},
+			{"id": "booking-theirs", "eventSlot": {"id": "slot-theirs", "start": "2025-03-03T15:00:00Z", "end": "2025-03-03T16:00:00Z",
+				"event": {"eventUserRole": "VERIFIER"}}}
+		]
+	}]`), &events)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]client.EventKind{
+		"free":           client.EventSlot,
+		"checked":        client.EventReviewed,
+		"checking":       client.EventReview,
+		"booking-ours":   client.EventReviewed,
+		"booking-theirs": client.EventReview,
+	}
+	busy := client.BusyEvents(events)
+	if len(busy) != len(want) {
+		t.Fatalf("BusyEvents() = %v, want %d events", busy, len(want))
+	}
+	for _, e := range busy {
+		if e.Kind != want[e.ID] {
+			t.Errorf("event %s kind = %s, want %s", e.ID, e.Kind, want[e.ID])
+		}
+	}
+
+	day := client.Interval{Start: time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC), End: time.Date(2025, 3, 4, 0, 0, 0, 0, time.UTC)}
+	blocked, _ := client.DefaultConflictPolicy.Check(day, busy)
+	if len(blocked) != 2 || blocked[0].Kind != client.EventReviewed || blocked[1].Kind != client.EventReviewed {
+		t.Errorf("default policy blocked %v, want the two reviews of our projects", blocked)
+	}
+}
+
+func TestMockClient_ConflictsIgnoreTheMovedSlot(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+		"slot-2": {start: base.Add(3 * time.Hour), end: base.Add(4 * time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	c.SetConflictAction(client.EventSlot, client.ConflictBlock)
+	ctx := context.Background()
+
+	if _, err := c.UpdateReviewSlot(ctx, "slot-1", base.Add(30*time.Minute), base.Add(90*time.Minute)); err != nil {
+		t.Fatalf("UpdateReviewSlot() over its own time error = %v", err)
+	}
+	var cErr *client.ConflictError
+	_, err := c.UpdateReviewSlot(ctx, "slot-1", base.Add(150*time.Minute), base.Add(210*time.Minute))
+	if !errors.As(err, &cErr) || len(cErr.Conflicts) != 1 || cErr.Conflicts[0].ID != "slot-2" {
+		t.Errorf("UpdateReviewSlot() onto slot-2 error = %v, want a conflict with slot-2", err)
+	}
+}
@@ -0,0 +1,87 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestMockClient_EnsureReviewSlotIsIdempotent(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{})
+	c := newSlotClient(t, ss)
+	ctx := context.Background()
+
+	first, err := c.EnsureReviewSlot(ctx, base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("EnsureReviewSlot() error = %v", err)
+	}
+	if !first.Changed() || len(first.Added) != 1 || len(first.Slots) != 1 {
+		t.Fatalf("First EnsureReviewSlot() = %+v, want one added slot", first)
+	}
+
+	second, err := c.EnsureReviewSlot(ctx, base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("Second EnsureReviewSlot() error = %v", err)
+	}
+	if second.Changed() {
+		t.Errorf("Second EnsureReviewSlot() changed the calendar: %+v", second)
+	}
+	if len(second.Slots) != 1 || second.Slots[0].ID != first.Added[0].ID {
+		t.Errorf("Second EnsureReviewSlot() slots = %+v, want the slot added first", second.Slots)
+	}
+	if len(ss.slots) != 1 {
+		t.Errorf("Calendar has %d slots, want 1", len(ss.slots))
+	}
+}
+
+func TestMockClient_EnsureReviewSlotExtendsAdjacentFreeSlot(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+
+	res, err := c.EnsureReviewSlot(context.Background(), base.Add(30*time.Minute), base.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("EnsureReviewSlot() error = %v", err)
+	}
+	if len(res.Added) != 0 || len(res.Extended) != 1 {
+		t.Fatalf("EnsureReviewSlot() = %+v, want slot-1 extended and nothing added", res)
+	}
+	s1, _ := ss.slot("slot-1")
+	if !s1.start.Equal(base) || !s1.end.Equal(base.Add(2*time.Hour)) {
+		t.Errorf("slot-1 = %s - %s, want it stretched to the end of the interval", s1.start, s1.end)
+	}
+	if len(res.Slots) != 1 || res.Slots[0].ID != "slot-1" {
+		t.Errorf("Covering slots = %+v, want slot-1", res.Slots)
+	}
+}
+
+func TestMockClient_EnsureReviewSlotAddsNextToBookedSlot(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour), booked: true},
+	})
+	c := newSlotClient(t, ss)
+
+	res, err := c.EnsureReviewSlot(context.Background(), base, base.Add(2*time.Hour))
+	if err != nil {
+		t.Fatalf("EnsureReviewSlot() error = %v", err)
+	}
+	if len(res.Extended) != 0 || len(res.Added) != 1 {
+		t.Fatalf("EnsureReviewSlot() = %+v, want only the uncovered hour added", res)
+	}
+	want := client.Interval{Start: base.Add(time.Hour), End: base.Add(2 * time.Hour)}
+	if !res.Added[0].Interval().Equal(want) {
+		t.Errorf("Added slot = %s - %s, want %s - %s", res.Added[0].Start, res.Added[0].End, want.Start, want.End)
+	}
+	if len(res.Slots) != 2 {
+		t.Errorf("Covering slots = %+v, want the booked slot and the new one", res.Slots)
+	}
+}
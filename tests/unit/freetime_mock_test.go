@@ -0,0 +1,103 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestFindFree(t *testing.T) {
+	window := hours(8, 20)
+	min := func(m int) time.Duration { return time.Duration(m) * time.Minute }
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	busy := []client.BusyEvent{
+		{Interval: hours(10, 11), Kind: client.EventExam, ID: "exam"},
+		{Interval: hours(13, 14), Kind: client.EventSlot, ID: "slot"},
+		{Interval: hours(16, 17), Kind: client.EventReview, ID: "review"},
+	}
+
+	tests := []struct {
+		name        string
+		window      client.Interval
+		constraints client.FreeTimeConstraints
+		want        []client.Interval
+	}{
+		{"every gap as is", window, client.FreeTimeConstraints{},
+			[]client.Interval{hours(8, 10), hours(11, 13), hours(14, 16), hours(17, 20)}},
+		{"buffer around busy events", window, client.FreeTimeConstraints{Buffer: min(15)},
+			[]client.Interval{hours(8, 9.75), hours(11.25, 12.75), hours(14.25, 15.75), hours(17.25, 20)}},
+		{"ignored kinds are not busy", window, client.FreeTimeConstraints{Ignore: []client.EventKind{client.EventSlot}},
+			[]client.Interval{hours(8, 10), hours(11, 16), hours(17, 20)}},
+		{"gaps shorter than the minimum dropped", window, client.FreeTimeConstraints{MinLength: 150 * time.Minute},
+			[]client.Interval{hours(17, 20)}},
+		{"long gaps cut into pieces", hours(17, 20), client.FreeTimeConstraints{MaxLength: time.Hour},
+			[]client.Interval{hours(17, 18), hours(18, 19), hours(19, 20)}},
+		{"pieces shorter than the minimum dropped", hours(17, 20), client.FreeTimeConstraints{MaxLength: min(75), MinLength: time.Hour},
+			[]client.Interval{hours(17, 18.25), hours(18.25, 19.5)}},
+		{"gaps rounded inwards to the step", window, client.FreeTimeConstraints{Buffer: min(10), Granularity: min(30)},
+			[]client.Interval{hours(8, 9.5), hours(11.5, 12.5), hours(14.5, 15.5), hours(17.5, 20)}},
+		{"working hours", hours(0, 48), client.FreeTimeConstraints{DayStart: 9 * time.Hour, DayEnd: 12 * time.Hour},
+			[]client.Interval{hours(9, 10), hours(11, 12), hours(33, 36)}},
+		{"working hours in another zone", hours(0, 24), client.FreeTimeConstraints{DayStart: 9 * time.Hour, DayEnd: 12 * time.Hour, Location: berlin},
+			[]client.Interval{hours(8, 10)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.FindFree(tt.window, busy, tt.constraints); !sameIntervals(got, tt.want) {
+				t.Errorf("FindFree() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockClient_FindFreeTime(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base.Add(time.Hour), end: base.Add(2 * time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	ctx := context.Background()
+
+	at := func(h float64) time.Time { return base.Add(time.Duration(h * float64(time.Hour))) }
+
+	gaps, err := c.FindFreeTime(ctx, base, base.Add(4*time.Hour), client.FreeTimeConstraints{})
+	if err != nil {
+		t.Fatalf("FindFreeTime() error = %v", err)
+	}
+	want := []client.Interval{{Start: at(0), End: at(1)}, {Start: at(2), End: at(4)}}
+	if !sameIntervals(gaps, want) {
+		t.Errorf("FindFreeTime() = %v, want %v", gaps, want)
+	}
+
+	// The slot rules narrow the range and fill in the length and step
+	c = newSlotClient(t, ss, client.WithSlotRules(client.SlotRules{MaxDuration: 90 * time.Minute, Granularity: 15 * time.Minute, MaxHorizon: time.Until(at(3))}))
+	gaps, err = c.FindFreeTime(ctx, base, at(4), client.FreeTimeConstraints{Ignore: []client.EventKind{client.EventSlot}})
+	if err != nil {
+		t.Fatalf("FindFreeTime() error = %v", err)
+	}
+	want = []client.Interval{{Start: at(0), End: at(1.5)}, {Start: at(1.5), End: at(3)}}
+	if !sameIntervals(gaps, want) {
+		t.Errorf("FindFreeTime() with slot rules = %v, want %v", gaps, want)
+	}
+}
+
+func sameIntervals(a, b []client.Interval) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !a[i].Equal(b[i]) {
+			return false
+		}
+	}
+	return true
+}
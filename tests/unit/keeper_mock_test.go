@@ -0,0 +1,58 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/guard"
+)
+
+func TestKeeper_PlanTopsUpAroundEventsAndBookings(t *testing.T) {
+	day := time.Date(2025, 1, 13, 0, 0, 0, 0, time.UTC)
+	at := func(d int, h, m int) time.Time {
+		return day.AddDate(0, 0, d).Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute)
+	}
+	now := at(0, 8, 0)
+
+	k := guard.NewKeeper(2, 2*time.Hour, 3*time.Hour, []guard.Window{{Start: 10 * time.Hour, End: 20 * time.Hour}})
+	k.Location = time.UTC
+	k.Buffer = 15 * time.Minute
+
+	slots := []client.ReviewSlot{
+		{ID: "booked-1", Start: at(0, 10, 0), End: at(0, 11, 0), Type: client.SlotTypeBooked},
+	}
+	busy := []client.BusyEvent{
+		{Interval: client.Interval{Start: at(0, 10, 0), End: at(0, 11, 0)}, Kind: client.EventReview},
+		{Interval: client.Interval{Start: at(0, 11, 0), End: at(0, 13, 0)}, Kind: client.EventExam},
+	}
+
+	actions := k.Plan(now, slots, busy, nil, nil)
+	want := []client.Interval{
+		{Start: at(0, 13, 15), End: at(0, 14, 15)}, // 1h booked, the exam and its buffer skipped
+		{Start: at(1, 10, 0), End: at(1, 12, 0)},
+	}
+	if len(actions) != len(want) {
+		t.Fatalf("Plan() = %v, want %d additions", actions, len(want))
+	}
+	for i, a := range actions {
+		if a.Kind != guard.ActionAdd || !a.Before.Equal(want[i]) {
+			t.Errorf("Plan()[%d] = %s, want add %s - %s", i, a, want[i].Start, want[i].End)
+		}
+	}
+
+	// Blacked-out time is skipped like a busy event, without the buffer
+	blocked := []client.Interval{{Start: at(0, 13, 0), End: at(0, 14, 0)}}
+	if actions := k.Plan(now, slots, busy, blocked, nil); len(actions) == 0 || !actions[0].Before.Start.Equal(at(0, 14, 0)) {
+		t.Errorf("Plan() around a blackout = %v, want the first slot at 14:00", actions)
+	}
+
+	// A day at its maximum gets nothing, even below a higher target
+	k.Target, k.Max = 4*time.Hour, time.Hour
+	if actions := k.Plan(now, slots, busy, nil, nil); len(actions) != 1 || actions[0].Before.Start.Day() != 14 {
+		t.Errorf("Plan() with a 1h maximum = %v, want only the second day topped up", actions)
+	}
+}
@@ -0,0 +1,217 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestMergeGroups(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	slot := func(id string, from, to int, typ string) client.ReviewSlot {
+		return client.ReviewSlot{ID: id, Start: base.Add(time.Duration(from) * time.Hour), End: base.Add(time.Duration(to) * time.Hour), Type: typ}
+	}
+	free, booked := client.SlotTypeFree, client.SlotTypeBooked
+
+	tests := []struct {
+		name  string
+		slots []client.ReviewSlot
+		want  [][]string
+	}{
+		{"none", nil, nil},
+		{"apart", []client.ReviewSlot{slot("a", 0, 1, free), slot("b", 2, 3, free)}, nil},
+		{"adjacent", []client.ReviewSlot{slot("a", 0, 1, free), slot("b", 1, 2, free)}, [][]string{{"a", "b"}}},
+		{"overlapping, out of order", []client.ReviewSlot{slot("b", 1, 3, free), slot("a", 0, 2, free)}, [][]string{{"a", "b"}}},
+		{"contained", []client.ReviewSlot{slot("a", 0, 4, free), slot("b", 1, 2, free), slot("c", 3, 5, free)}, [][]string{{"a", "b", "c"}}},
+		{"booked in between", []client.ReviewSlot{slot("a", 0, 1, free), slot("x", 1, 2, booked), slot("b", 2, 3, free)}, nil},
+		{"two groups", []client.ReviewSlot{slot("a", 0, 1, free), slot("b", 1, 2, free), slot("c", 5, 6, free), slot("d", 6, 7, free)}, [][]string{{"a", "b"}, {"c", "d"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := client.MergeGroups(tt.slots)
+			if len(groups) != len(tt.want) {
+				t.Fatalf("MergeGroups() = %d groups, want %d: %+v", len(groups), len(tt.want), groups)
+			}
+			for i, g := range groups {
+				if len(g) != len(tt.want[i]) {
+					t.Fatalf("Group %d has %d slots, want %v", i, len(g), tt.want[i])
+				}
+				for j, s := range g {
+					if s.ID != tt.want[i][j] {
+						t.Errorf("Group %d slot %d = %s, want %s", i, j, s.ID, tt.want[i][j])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSplitInterval(t *testing.T) {
+	base := time.Date(2025, 1, 15, 10, 0, 0, 0, time.UTC)
+	iv := func(from, to time.Duration) client.Interval {
+		return client.Interval{Start: base.Add(from), End: base.Add(to)}
+	}
+
+	tests := []struct {
+		name  string
+		iv    client.Interval
+		every time.Duration
+		want  []client.Interval
+	}{
+		{"even", iv(0, 2*time.Hour), time.Hour, []client.Interval{iv(0, time.Hour), iv(time.Hour, 2*time.Hour)}},
+		{"remainder", iv(0, 90*time.Minute), time.Hour, []client.Interval{iv(0, time.Hour), iv(time.Hour, 90*time.Minute)}},
+		{"shorter than a piece", iv(0, 30*time.Minute), time.Hour, []client.Interval{iv(0, 30*time.Minute)}},
+		{"no length", iv(0, time.Hour), 0, []client.Interval{iv(0, time.Hour)}},
+		{"empty", iv(0, 0), time.Hour, []client.Interval{iv(0, 0)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := client.SplitInterval(tt.iv, tt.every)
+			if len(got) != len(tt.want) {
+				t.Fatalf("SplitInterval() = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if !got[i].Equal(tt.want[i]) {
+					t.Errorf("Piece %d = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMockClient_MergeSlots(t *testing.T) {
+	base := slotBase()
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+
+	tests := []struct {
+		name       string
+		slots      map[string]*mockSlot
+		failChange bool
+		wantErr    func(error) bool
+		want       map[string]client.Interval // slots left afterwards, by start hour
+	}{
+		{
+			name:  "merges into the first slot",
+			slots: map[string]*mockSlot{"slot-1": {start: at(0), end: at(1)}, "slot-2": {start: at(1), end: at(3)}},
+			want:  map[string]client.Interval{"slot-1": {Start: at(0), End: at(3)}},
+		},
+		{
+			name:  "too long, nothing removed",
+			slots: map[string]*mockSlot{"slot-1": {start: at(0), end: at(7)}, "slot-2": {start: at(7), end: at(14)}},
+			wantErr: func(err error) bool {
+				var vErr *client.ValidationError
+				return errors.As(err, &vErr) && vErr.Has(client.RuleMaxDuration)
+			},
+			want: map[string]client.Interval{"slot-1": {Start: at(0), End: at(7)}, "slot-2": {Start: at(7), End: at(14)}},
+		},
+		{
+			name:       "failed extension, removed slot restored",
+			slots:      map[string]*mockSlot{"slot-1": {start: at(0), end: at(1)}, "slot-2": {start: at(1), end: at(2)}},
+			failChange: true,
+			wantErr:    func(err error) bool { return err != nil },
+			want:       map[string]client.Interval{"slot-1": {Start: at(0), End: at(1)}, "slot-new-1": {Start: at(1), End: at(2)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := newSlotServer(tt.slots)
+			ss.failChange = tt.failChange
+			c := newSlotClient(t, ss)
+			c.SetSlotValidation(true)
+			c.SetSlotRules(client.DefaultSlotRules)
+
+			group := []client.ReviewSlot{
+				{ID: "slot-1", Start: tt.slots["slot-1"].start, End: tt.slots["slot-1"].end, Type: client.SlotTypeFree},
+				{ID: "slot-2", Start: tt.slots["slot-2"].start, End: tt.slots["slot-2"].end, Type: client.SlotTypeFree},
+			}
+			_, err := c.MergeSlots(context.Background(), group)
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !tt.wantErr(err) {
+				t.Fatalf("MergeSlots() error = %v", err)
+			}
+
+			if len(ss.slots) != len(tt.want) {
+				t.Errorf("Calendar has %d slots, want %d", len(ss.slots), len(tt.want))
+			}
+			for id, want := range tt.want {
+				s, ok := ss.slot(id)
+				if !ok || !s.start.Equal(want.Start) || !s.end.Equal(want.End) {
+					t.Errorf("Slot %s = %+v (found %v), want %s - %s", id, s, ok, want.Start, want.End)
+				}
+			}
+		})
+	}
+}
+
+func TestMockClient_SplitReviewSlot(t *testing.T) {
+	base := slotBase()
+	at := func(m int) time.Time { return base.Add(time.Duration(m) * time.Minute) }
+
+	tests := []struct {
+		name      string
+		end       int // minutes after the start of slot-1
+		every     time.Duration
+		failAddAt int
+		wantErr   func(error) bool
+		want      map[string]client.Interval
+	}{
+		{
+			name:  "splits into pieces",
+			end:   180,
+			every: time.Hour,
+			want: map[string]client.Interval{
+				"slot-1":     {Start: at(0), End: at(60)},
+				"slot-new-1": {Start: at(60), End: at(120)},
+				"slot-new-2": {Start: at(120), End: at(180)},
+			},
+		},
+		{
+			name:  "tail shorter than the minimum, nothing changed",
+			end:   75,
+			every: 30 * time.Minute,
+			wantErr: func(err error) bool {
+				var vErr *client.ValidationError
+				return errors.As(err, &vErr) && vErr.Has(client.RuleMinDuration)
+			},
+			want: map[string]client.Interval{"slot-1": {Start: at(0), End: at(75)}},
+		},
+		{
+			name:      "failed add, original slot restored",
+			end:       180,
+			every:     time.Hour,
+			failAddAt: 2,
+			wantErr:   func(err error) bool { return err != nil },
+			want:      map[string]client.Interval{"slot-1": {Start: at(0), End: at(180)}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := newSlotServer(map[string]*mockSlot{"slot-1": {start: at(0), end: at(tt.end)}})
+			ss.failAddAt = tt.failAddAt
+			c := newSlotClient(t, ss)
+			c.SetSlotValidation(true)
+			c.SetSlotRules(client.DefaultSlotRules)
+
+			slot := client.ReviewSlot{ID: "slot-1", Start: at(0), End: at(tt.end), Type: client.SlotTypeFree}
+			_, err := c.SplitReviewSlot(context.Background(), slot, tt.every)
+			if tt.wantErr == nil && err != nil || tt.wantErr != nil && !tt.wantErr(err) {
+				t.Fatalf("SplitReviewSlot() error = %v", err)
+			}
+
+			if len(ss.slots) != len(tt.want) {
+				t.Errorf("Calendar has %d slots, want %d", len(ss.slots), len(tt.want))
+			}
+			for id, want := range tt.want {
+				s, ok := ss.slot(id)
+				if !ok || !s.start.Equal(want.Start) || !s.end.Equal(want.End) {
+					t.Errorf("Slot %s = %+v (found %v), want %s - %s", id, s, ok, want.Start, want.End)
+				}
+			}
+		})
+	}
+}
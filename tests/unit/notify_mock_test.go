@@ -0,0 +1,132 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/notify"
+)
+
+func TestNotify_LoadHooks(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	if hooks, err := notify.LoadHooks(filepath.Join(dir, "missing.json")); err != nil || hooks != nil {
+		t.Errorf("LoadHooks() of a missing file = %v, %v; want no hooks", hooks, err)
+	}
+
+	hooks, err := notify.LoadHooks(write("ok.json", `[
+		{"type": "exec", "command": "true", "events": ["BookingCreated"]},
+		{"type": "webhook", "url": "http://localhost", "secret": "s"}
+	]`))
+	if err != nil || len(hooks) != 2 {
+		t.Fatalf("LoadHooks() = %v, %v; want 2 hooks", hooks, err)
+	}
+	if !hooks[0].Wants(notify.BookingCreated) || hooks[0].Wants(notify.BookingCancelled) {
+		t.Error("exec hook should only want BookingCreated")
+	}
+	if !hooks[1].Wants(notify.BookingCancelled) {
+		t.Error("hook without events should want every event")
+	}
+
+	for name, content := range map[string]string{
+		"no-command.json": `[{"type": "exec"}]`,
+		"no-url.json":     `[{"type": "webhook"}]`,
+		"unknown.json":    `[{"type": "email", "url": "x"}]`,
+		"broken.json":     `[{"type": `,
+	} {
+		if _, err := notify.LoadHooks(write(name, content)); err == nil {
+			t.Errorf("LoadHooks(%s) error = nil, want an error", name)
+		}
+	}
+}
+
+func TestNotify_DispatchSignsWebhooksAndRunsCommands(t *testing.T) {
+	var mu sync.Mutex
+	var gotBody []byte
+	var gotSignature string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		gotBody, _ = io.ReadAll(r.Body)
+		gotSignature = r.Header.Get(notify.SignatureHeader)
+	}))
+	defer server.Close()
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	out := filepath.Join(t.TempDir(), "event.json")
+	d := notify.NewDispatcher([]notify.Hook{
+		{Type: notify.HookExec, Command: "exit 3"},
+		{Type: notify.HookExec, Command: `cat > "` + out + `"; echo "$S21_EVENT" >> "` + out + `"`},
+		{Type: notify.HookWebhook, URL: failing.URL},
+		{Type: notify.HookWebhook, URL: server.URL, Secret: "secret"},
+		{Type: notify.HookWebhook, URL: server.URL + "/cancelled", Events: []notify.EventType{notify.BookingCancelled}},
+	})
+
+	base := slotBase()
+	e, ok := notify.BookingEvent(client.CalendarChange{
+		Type:         client.BookingCreated,
+		DetectedAt:   base,
+		BookingAfter: &client.ReviewBooking{ID: "b1", SlotID: "slot-1", ProjectName: "C2_s21_stringplus", Start: base, End: base.Add(time.Hour)},
+	})
+	if !ok || e.Type != notify.BookingCreated || e.BookingID != "b1" || !e.DetectedAt.Equal(base) {
+		t.Fatalf("BookingEvent() = %+v, %v", e, ok)
+	}
+
+	err := d.Dispatch(context.Background(), e)
+	if err == nil || !strings.Contains(err.Error(), "hook 1 (exec)") || !strings.Contains(err.Error(), "hook 3 (webhook)") {
+		t.Fatalf("Dispatch() error = %v, want hooks 1 and 3 to fail", err)
+	}
+	if strings.Contains(err.Error(), "hook 2") || strings.Contains(err.Error(), "hook 4") {
+		t.Errorf("Dispatch() error = %v, want hooks 2 and 4 to succeed", err)
+	}
+
+	written, readErr := os.ReadFile(out)
+	if readErr != nil {
+		t.Fatalf("exec hook output: %v", readErr)
+	}
+	if !strings.Contains(string(written), `"bookingId":"b1"`) || !strings.HasSuffix(string(written), "BookingCreated\n") {
+		t.Errorf("exec hook got %q, want the event JSON and S21_EVENT", written)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write(gotBody)
+	if want := "sha256=" + hex.EncodeToString(mac.Sum(nil)); gotSignature != want {
+		t.Errorf("signature = %q, want %q", gotSignature, want)
+	}
+	var decoded notify.Event
+	if err := json.Unmarshal(gotBody, &decoded); err != nil || decoded.ProjectName != "C2_s21_stringplus" {
+		t.Errorf("webhook body = %s, %v", gotBody, err)
+	}
+
+	if _, ok := notify.BookingEvent(client.CalendarChange{Type: client.SlotAdded}); ok {
+		t.Error("BookingEvent() of a slot change should report no event")
+	}
+}
@@ -0,0 +1,91 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+var (
+	fragmentDef    = regexp.MustCompile(`(?m)^fragment (\w+) on \w+ \{`)
+	fragmentSpread = regexp.MustCompile(`\.\.\.(\w+)`)
+)
+
+// TestOperations_WellFormed catches the mistakes the mock servers cannot:
+// they never parse the documents, the real API rejects the whole request
+func TestOperations_WellFormed(t *testing.T) {
+	operations := map[string]string{
+		"QueryGetUserNotifications":              client.QueryGetUserNotifications,
+		"QueryGetUserNotificationsCount":         client.QueryGetUserNotificationsCount,
+		"QueryGetCurrentUser":                    client.QueryGetCurrentUser,
+		"QueryProjectMapGetStudentStageGroups":   client.QueryProjectMapGetStudentStageGroups,
+		"QueryProjectMapGetStudentGraphTemplate": client.QueryProjectMapGetStudentGraphTemplate,
+		"QueryCalendarGetMyReviews":              client.QueryCalendarGetMyReviews,
+		"QueryCalendarGetEvents":                 client.QueryCalendarGetEvents,
+		"MutationCalendarDeleteEventSlot":        client.MutationCalendarDeleteEventSlot,
+		"MutationCalendarChangeEventSlot":        client.MutationCalendarChangeEventSlot,
+		"MutationCalendarAddEvent":               client.MutationCalendarAddEvent,
+	}
+
+	for name, doc := range operations {
+		t.Run(name, func(t *testing.T) {
+			for _, pair := range [][2]rune{{'{', '}'}, {'(', ')'}} {
+				depth := 0
+				for i, r := range doc {
+					switch r {
+					case pair[0]:
+						depth++
+					case pair[1]:
+						depth--
+					}
+					if depth < 0 {
+						t.Fatalf("unmatched %q at offset %d", pair[1], i)
+					}
+					// Definitions start at the top level only
+					if depth > 0 && i+1 < len(doc) && doc[i] == '\n' && hasDefinitionAt(doc[i+1:]) {
+						t.Fatalf("definition at offset %d starts inside an unclosed %q", i+1, pair[0])
+					}
+				}
+				if depth != 0 {
+					t.Fatalf("%d unclosed %q", depth, pair[0])
+				}
+			}
+
+			defined := make(map[string]bool)
+			for _, m := range fragmentDef.FindAllStringSubmatch(doc, -1) {
+				if defined[m[1]] {
+					t.Errorf("fragment %s defined twice", m[1])
+				}
+				defined[m[1]] = true
+			}
+			used := make(map[string]bool)
+			for _, m := range fragmentSpread.FindAllStringSubmatch(doc, -1) {
+				if m[1] == "on" {
+					continue // inline fragment
+				}
+				used[m[1]] = true
+				if !defined[m[1]] {
+					t.Errorf("fragment %s is used but not defined", m[1])
+				}
+			}
+			for f := range defined {
+				if !used[f] {
+					t.Errorf("fragment %s is defined but never used", f)
+				}
+			}
+		})
+	}
+}
+
+func hasDefinitionAt(s string) bool {
+	for _, kw := range []string{"fragment ", "query ", "mutation "} {
+		if len(s) >= len(kw) && s[:len(kw)] == kw {
+			return true
+		}
+	}
+	return false
+}
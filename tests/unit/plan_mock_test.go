@@ -0,0 +1,164 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/plan"
+)
+
+// planDay is the day the plan tests work on; hours are offsets into it
+var planDay = time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+
+func hours(from, to float64) client.Interval {
+	at := func(h float64) time.Time { return planDay.Add(time.Duration(h * float64(time.Hour))) }
+	return client.Interval{Start: at(from), End: at(to)}
+}
+
+func formatHours(iv client.Interval) string {
+	return iv.Start.Format("15:04") + "-" + iv.End.Format("15:04")
+}
+
+func TestMergeIntervals(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []client.Interval
+		want []client.Interval
+	}{
+		{"empty", nil, nil},
+		{"disjoint stay apart, sorted", []client.Interval{hours(13, 14), hours(10, 11)},
+			[]client.Interval{hours(10, 11), hours(13, 14)}},
+		{"overlapping join", []client.Interval{hours(10, 12), hours(11, 13)}, []client.Interval{hours(10, 13)}},
+		{"adjacent join", []client.Interval{hours(10, 11), hours(11, 12)}, []client.Interval{hours(10, 12)}},
+		{"contained is absorbed", []client.Interval{hours(10, 14), hours(11, 12)}, []client.Interval{hours(10, 14)}},
+		{"empty intervals dropped", []client.Interval{hours(10, 10), hours(12, 11), hours(13, 14)},
+			[]client.Interval{hours(13, 14)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.MergeIntervals(tt.in); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("MergeIntervals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubtractIntervals(t *testing.T) {
+	tests := []struct {
+		name   string
+		from   []client.Interval
+		remove []client.Interval
+		want   []client.Interval
+	}{
+		{"nothing removed", []client.Interval{hours(10, 12)}, nil, []client.Interval{hours(10, 12)}},
+		{"middle cut out", []client.Interval{hours(10, 14)}, []client.Interval{hours(11, 12)},
+			[]client.Interval{hours(10, 11), hours(12, 14)}},
+		{"start cut off", []client.Interval{hours(10, 14)}, []client.Interval{hours(9, 11)}, []client.Interval{hours(11, 14)}},
+		{"end cut off", []client.Interval{hours(10, 14)}, []client.Interval{hours(13, 15)}, []client.Interval{hours(10, 13)}},
+		{"fully covered", []client.Interval{hours(10, 12)}, []client.Interval{hours(9, 13)}, nil},
+		{"adjacent remove leaves it whole", []client.Interval{hours(10, 12)}, []client.Interval{hours(12, 13)},
+			[]client.Interval{hours(10, 12)}},
+		{"overlapping inputs merged first", []client.Interval{hours(10, 12), hours(11, 14)},
+			[]client.Interval{hours(12, 13), hours(12, 13)}, []client.Interval{hours(10, 12), hours(13, 14)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := client.SubtractIntervals(tt.from, tt.remove); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("SubtractIntervals() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlan_Compute(t *testing.T) {
+	window := hours(8, 20)
+	free := func(id string, iv client.Interval) client.ReviewSlot {
+		return client.ReviewSlot{ID: id, Start: iv.Start, End: iv.End, Type: client.SlotTypeFree}
+	}
+	booked := func(id string, iv client.Interval) client.ReviewSlot {
+		s := free(id, iv)
+		s.Type = "BOOKED_TIME"
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		desired  []client.Interval
+		slots    []client.ReviewSlot
+		bookings []client.ReviewBooking
+		want     []string
+	}{
+		{"create where nothing is",
+			[]client.Interval{hours(10, 12)}, nil, nil,
+			[]string{"create 10:00-12:00"}},
+		{"overlapping desired intervals make one slot",
+			[]client.Interval{hours(10, 12), hours(11, 13)}, nil, nil,
+			[]string{"create 10:00-13:00"}},
+		{"adjacent desired intervals make one slot",
+			[]client.Interval{hours(10, 11), hours(11, 12)}, nil, nil,
+			[]string{"create 10:00-12:00"}},
+		{"resize rather than create",
+			[]client.Interval{hours(10, 12)}, []client.ReviewSlot{free("s1", hours(10, 11))}, nil,
+			[]string{"resize s1 10:00-12:00"}},
+		{"keep a matching slot",
+			[]client.Interval{hours(10, 12)}, []client.ReviewSlot{free("s1", hours(10, 12))}, nil,
+			[]string{"keep s1 10:00-12:00"}},
+		{"adjacent slots merged into the larger",
+			[]client.Interval{hours(10, 13)}, []client.ReviewSlot{free("s1", hours(10, 11)), free("s2", hours(11, 13))}, nil,
+			[]string{"delete s1", "resize s2 10:00-13:00"}},
+		{"free slot outside the desired availability removed",
+			[]client.Interval{hours(10, 11)}, []client.ReviewSlot{free("s1", hours(10, 11)), free("s2", hours(15, 16))}, nil,
+			[]string{"delete s2", "keep s1 10:00-11:00"}},
+		{"booked slot left untouched and planned around",
+			[]client.Interval{hours(10, 13)}, []client.ReviewSlot{booked("b1", hours(11, 12))}, nil,
+			[]string{"create 10:00-11:00", "create 12:00-13:00", "keep b1 11:00-12:00"}},
+		{"booking planned around",
+			[]client.Interval{hours(10, 12)}, nil, []client.ReviewBooking{{ID: "r1", Start: hours(10, 11).Start, End: hours(10, 11).End}},
+			[]string{"create 11:00-12:00"}},
+		{"desired time clipped to the window",
+			[]client.Interval{hours(18, 22), hours(5, 6)}, nil, nil,
+			[]string{"create 18:00-20:00"}},
+		{"slot sticking out of the window kept",
+			[]client.Interval{hours(19, 20)}, []client.ReviewSlot{free("s1", hours(19, 21))}, nil,
+			[]string{"keep s1 19:00-21:00"}},
+		{"slot outside the window ignored",
+			nil, []client.ReviewSlot{free("s1", hours(21, 22)), free("s2", hours(6, 8))}, nil,
+			nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := plan.Compute(window, tt.desired, tt.slots, tt.bookings)
+
+			var got []string
+			for _, a := range p.Actions {
+				s := string(a.Kind)
+				if a.SlotID != "" {
+					s += " " + a.SlotID
+				}
+				if a.To != nil {
+					s += " " + formatHours(*a.To)
+				}
+				got = append(got, s)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Compute() actions = %q, want %q", got, tt.want)
+			}
+			changes := false
+			for _, a := range got {
+				changes = changes || !strings.HasPrefix(a, "keep")
+			}
+			if p.HasChanges() != changes {
+				t.Errorf("HasChanges() = %v for %q", p.HasChanges(), got)
+			}
+		})
+	}
+}
@@ -0,0 +1,102 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/guard"
+)
+
+func TestPruneSoon_Plan(t *testing.T) {
+	now := time.Date(2025, 3, 3, 10, 7, 0, 0, time.UTC)
+	at := func(h, m int) time.Time { return time.Date(2025, 3, 3, h, m, 0, 0, time.UTC) }
+	slot := func(id string, start, end time.Time) client.ReviewSlot {
+		return client.ReviewSlot{ID: id, Start: start, End: end, Type: client.SlotTypeFree}
+	}
+	booked := slot("booked", at(10, 30), at(11, 30))
+	booked.Type = "BOOKED_TIME"
+
+	tests := []struct {
+		name  string
+		trim  bool
+		slots []client.ReviewSlot
+		want  []string // "kind id" or "trim id start-end"
+	}{
+		{"slot within the lead time removed", false,
+			[]client.ReviewSlot{slot("s1", at(10, 30), at(11, 30))}, []string{"remove s1"}},
+		{"slot already started removed", false,
+			[]client.ReviewSlot{slot("s1", at(9, 30), at(10, 30))}, []string{"remove s1"}},
+		{"slot after the lead time left alone", false,
+			[]client.ReviewSlot{slot("s1", at(11, 7), at(12, 0)), slot("s2", at(11, 30), at(12, 0))}, nil},
+		{"slot that already ended left alone", false,
+			[]client.ReviewSlot{slot("s1", at(9, 0), at(10, 0))}, nil},
+		{"booked slot left alone", false, []client.ReviewSlot{booked}, nil},
+		{"long slot trimmed to the next step after the lead time", true,
+			[]client.ReviewSlot{slot("s1", at(10, 30), at(12, 0))}, []string{"trim s1 11:15-12:00"}},
+		{"trim exactly the minimum duration", true,
+			[]client.ReviewSlot{slot("s1", at(10, 30), at(11, 45))}, []string{"trim s1 11:15-11:45"}},
+		{"too short to trim removed", true,
+			[]client.ReviewSlot{slot("s1", at(10, 30), at(11, 30))}, []string{"remove s1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actions := guard.NewPruneSoon(time.Hour, tt.trim).Plan(now, tt.slots)
+			var got []string
+			for _, a := range actions {
+				s := string(a.Kind) + " " + a.SlotID
+				if a.After != nil {
+					s += " " + a.After.Start.Format("15:04") + "-" + a.After.End.Format("15:04")
+				}
+				got = append(got, s)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("Plan() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockPruneSoon_EnforceFallsBackToRemove(t *testing.T) {
+	now := time.Now()
+	base := now.Truncate(15 * time.Minute)
+	newServer := func() *slotServer {
+		return newSlotServer(map[string]*mockSlot{
+			"slot-1": {start: base.Add(15 * time.Minute), end: base.Add(3 * time.Hour)},
+		})
+	}
+	p := guard.NewPruneSoon(time.Hour, true)
+
+	ss := newServer()
+	actions, err := p.Enforce(context.Background(), newSlotClient(t, ss), now)
+	if err != nil {
+		t.Fatalf("Enforce() error = %v", err)
+	}
+	if len(actions) != 1 || actions[0].Kind != guard.ActionTrim || actions[0].Err != nil {
+		t.Fatalf("Enforce() = %+v, want slot-1 trimmed", actions)
+	}
+	if s, ok := ss.slot("slot-1"); !ok || !s.start.Equal(actions[0].After.Start) || s.start.Before(now.Add(time.Hour)) {
+		t.Errorf("slot-1 = %+v, want it to start after the lead time at %v", s, actions[0].After.Start)
+	}
+
+	// Rules the trimmed slot breaks make the policy remove it instead
+	ss = newServer()
+	c := newSlotClient(t, ss, client.WithSlotRules(client.SlotRules{MinDuration: 10 * time.Hour}))
+	c.SetSlotValidation(true)
+	actions, err = p.Enforce(context.Background(), c, now)
+	if err != nil {
+		t.Fatalf("Enforce() error = %v", err)
+	}
+	if len(actions) != 1 || actions[0].Kind != guard.ActionRemove || actions[0].Err != nil {
+		t.Fatalf("Enforce() = %+v, want slot-1 removed", actions)
+	}
+	if _, ok := ss.slot("slot-1"); ok {
+		t.Error("slot-1 still exists")
+	}
+}
@@ -0,0 +1,120 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/guard"
+)
+
+func TestQuota_Usage(t *testing.T) {
+	// Wednesday; the week started on Monday March 3
+	now := time.Date(2025, 3, 5, 10, 0, 0, 0, time.UTC)
+	at := func(day, hour int) time.Time { return time.Date(2025, 3, day, hour, 0, 0, 0, time.UTC) }
+	booking := func(id, login string, day, hour int) client.ReviewBooking {
+		return client.ReviewBooking{ID: id, VerifierLogin: login, Start: at(day, hour), End: at(day, hour+1)}
+	}
+
+	q := guard.NewQuota(2, 3, "me", "")
+	q.Location = time.UTC
+	q.Horizon = 3 * 24 * time.Hour
+	usage := q.Usage(now, []client.ReviewBooking{
+		booking("b1", "me", 5, 12),
+		booking("b2", "me", 5, 14),
+		booking("b2", "me", 5, 14), // listed twice
+		booking("b3", "someone", 5, 16),
+		booking("b4", "me", 6, 9),
+		booking("b5", "me", 12, 9), // beyond the horizon
+	})
+
+	want := []guard.Usage{
+		{Period: guard.PeriodDay, Start: at(5, 0), Count: 2, Cap: 2},
+		{Period: guard.PeriodDay, Start: at(6, 0), Count: 1, Cap: 2},
+		{Period: guard.PeriodDay, Start: at(7, 0), Count: 0, Cap: 2},
+		{Period: guard.PeriodDay, Start: at(8, 0), Count: 0, Cap: 2},
+		{Period: guard.PeriodWeek, Start: at(3, 0), Count: 3, Cap: 3},
+	}
+	if len(usage) != len(want) {
+		t.Fatalf("Usage() = %+v, want %+v", usage, want)
+	}
+	for i := range want {
+		u := usage[i]
+		if u.Period != want[i].Period || !u.Start.Equal(want[i].Start) || u.Count != want[i].Count || u.Cap != want[i].Cap {
+			t.Errorf("usage %d = %+v, want %+v", i, u, want[i])
+		}
+	}
+	if !usage[0].Full() || usage[1].Full() || !usage[4].Full() {
+		t.Errorf("Full() wrong for %+v", usage)
+	}
+	if (guard.Usage{Count: 5}).Full() {
+		t.Error("a period without a cap should never be full")
+	}
+}
+
+func TestMockQuota_WithdrawsAndRestoresSlots(t *testing.T) {
+	now := time.Now()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC).AddDate(0, 0, 2)
+	at := func(hour int) time.Time { return day.Add(time.Duration(hour) * time.Hour) }
+
+	ss := newSlotServer(map[string]*mockSlot{
+		"booked-1": {start: at(9), end: at(10), booked: true, verifier: "me"},
+		"other-1":  {start: at(10), end: at(11), booked: true, verifier: "someone"},
+		"slot-1":   {start: at(12), end: at(13)},
+		"slot-2":   {start: at(12 + 24), end: at(13 + 24)},
+	})
+	c := newSlotClient(t, ss)
+	ctx := context.Background()
+
+	state := filepath.Join(t.TempDir(), "withdrawn.json")
+	if err := guard.SaveWithdrawn(state, []guard.Withdrawn{{SlotID: "x", Slot: client.Interval{Start: at(15), End: at(16)}, Policy: "other"}}); err != nil {
+		t.Fatal(err)
+	}
+	q := guard.NewQuota(1, 0, "me", state)
+	q.Location = time.UTC
+	q.Horizon = 5 * 24 * time.Hour
+
+	actions, err := q.Enforce(ctx, c, now)
+	if err != nil {
+		t.Fatalf("Enforce() error = %v", err)
+	}
+	if len(actions) != 1 || actions[0].Kind != guard.ActionRemove || actions[0].SlotID != "slot-1" || actions[0].Err != nil {
+		t.Fatalf("Enforce() = %+v, want slot-1 withdrawn", actions)
+	}
+	if _, ok := ss.slot("slot-1"); ok {
+		t.Error("slot-1 still exists")
+	}
+	if _, ok := ss.slot("slot-2"); !ok {
+		t.Error("slot-2 on a day under the cap was withdrawn")
+	}
+	withdrawn, err := guard.LoadWithdrawn(state)
+	if err != nil || len(withdrawn) != 2 {
+		t.Fatalf("LoadWithdrawn() = %+v, %v; want slot-1 next to the other policy's slot", withdrawn, err)
+	}
+
+	// A cancellation brings the day back under the cap
+	ss.mu.Lock()
+	delete(ss.slots, "booked-1")
+	ss.mu.Unlock()
+
+	actions, err = q.Enforce(ctx, c, now)
+	if err != nil {
+		t.Fatalf("Enforce() error = %v", err)
+	}
+	if len(actions) != 1 || actions[0].Kind != guard.ActionRestore || actions[0].Err != nil {
+		t.Fatalf("Enforce() = %+v, want slot-1 restored", actions)
+	}
+	restored, ok := ss.slot(actions[0].SlotID)
+	if !ok || !restored.start.Equal(at(12)) || !restored.end.Equal(at(13)) {
+		t.Errorf("restored slot %s = %+v, want slot-1's times", actions[0].SlotID, restored)
+	}
+	withdrawn, err = guard.LoadWithdrawn(state)
+	if err != nil || len(withdrawn) != 1 || withdrawn[0].Policy != "other" {
+		t.Errorf("LoadWithdrawn() = %+v, %v; want only the other policy's slot", withdrawn, err)
+	}
+}
@@ -0,0 +1,117 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/recur"
+)
+
+func TestRule_Expand(t *testing.T) {
+	mustLoad := func(name string) *time.Location {
+		loc, err := time.LoadLocation(name)
+		if err != nil {
+			t.Skipf("no tzdata: %v", err)
+		}
+		return loc
+	}
+	moscow, berlin := mustLoad("Europe/Moscow"), mustLoad("Europe/Berlin")
+	date := func(loc *time.Location, month time.Month, day, hour int) time.Time {
+		return time.Date(2025, month, day, hour, 0, 0, 0, loc)
+	}
+
+	tests := []struct {
+		name     string
+		rule     recur.Rule
+		from, to time.Time
+		want     []string // starts as "01-02 15:04 -0700" in the rule's zone
+	}{
+		{"daily every other day",
+			recur.Rule{RRule: "FREQ=DAILY;INTERVAL=2", Start: date(time.UTC, 3, 1, 10), TZ: "UTC"},
+			date(time.UTC, 3, 1, 0), date(time.UTC, 3, 7, 0),
+			[]string{"03-01 10:00 +0000", "03-03 10:00 +0000", "03-05 10:00 +0000"}},
+		{"weekly every other week",
+			recur.Rule{RRule: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;BYHOUR=18", Start: date(time.UTC, 3, 3, 0), TZ: "UTC"},
+			date(time.UTC, 3, 1, 0), date(time.UTC, 3, 22, 0),
+			[]string{"03-03 18:00 +0000", "03-05 18:00 +0000", "03-17 18:00 +0000", "03-19 18:00 +0000"}},
+		{"excluded day still counts towards COUNT",
+			recur.Rule{RRule: "FREQ=DAILY;COUNT=3", Start: date(time.UTC, 3, 1, 10), TZ: "UTC", ExDates: []string{"2025-03-02"}},
+			date(time.UTC, 3, 1, 0), date(time.UTC, 3, 10, 0),
+			[]string{"03-01 10:00 +0000", "03-03 10:00 +0000"}},
+		{"excluded occurrence still counts towards COUNT",
+			recur.Rule{RRule: "FREQ=DAILY;BYHOUR=10,14;COUNT=4", Start: date(time.UTC, 3, 1, 0), TZ: "UTC",
+				ExDates: []string{"2025-03-01 14:00"}},
+			date(time.UTC, 3, 1, 0), date(time.UTC, 3, 10, 0),
+			[]string{"03-01 10:00 +0000", "03-02 10:00 +0000", "03-02 14:00 +0000"}},
+		{"UNTIL date includes the whole day in the rule's zone",
+			recur.Rule{RRule: "FREQ=DAILY;BYHOUR=22;UNTIL=20250303", Start: date(moscow, 3, 1, 0), TZ: "Europe/Moscow"},
+			date(moscow, 3, 1, 0), date(moscow, 3, 10, 0),
+			[]string{"03-01 22:00 +0300", "03-02 22:00 +0300", "03-03 22:00 +0300"}},
+		{"UNTIL local time is in the rule's zone",
+			recur.Rule{RRule: "FREQ=DAILY;BYHOUR=22;UNTIL=20250302T220000", Start: date(moscow, 3, 1, 0), TZ: "Europe/Moscow"},
+			date(moscow, 3, 1, 0), date(moscow, 3, 10, 0),
+			[]string{"03-01 22:00 +0300", "03-02 22:00 +0300"}},
+		{"UNTIL in UTC is inclusive",
+			recur.Rule{RRule: "FREQ=DAILY;BYHOUR=22;UNTIL=20250302T190000Z", Start: date(moscow, 3, 1, 0), TZ: "Europe/Moscow"},
+			date(moscow, 3, 1, 0), date(moscow, 3, 10, 0),
+			[]string{"03-01 22:00 +0300", "03-02 22:00 +0300"}},
+		{"clock time kept across the spring DST change",
+			recur.Rule{RRule: "FREQ=DAILY;BYHOUR=10", Start: date(berlin, 3, 29, 0), TZ: "Europe/Berlin"},
+			date(berlin, 3, 29, 0), date(berlin, 4, 1, 0),
+			[]string{"03-29 10:00 +0100", "03-30 10:00 +0200", "03-31 10:00 +0200"}},
+		{"clock time kept across the autumn DST change",
+			recur.Rule{RRule: "FREQ=WEEKLY;BYDAY=SU;BYHOUR=10", Start: date(berlin, 10, 19, 0), TZ: "Europe/Berlin"},
+			date(berlin, 10, 19, 0), date(berlin, 11, 3, 0),
+			[]string{"10-19 10:00 +0200", "10-26 10:00 +0100", "11-02 10:00 +0100"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.rule.Duration = recur.Duration(time.Hour)
+			intervals, err := tt.rule.Expand(tt.from, tt.to)
+			if err != nil {
+				t.Fatalf("Expand() error = %v", err)
+			}
+			loc, _ := time.LoadLocation(tt.rule.TZ)
+			var got []string
+			for _, iv := range intervals {
+				got = append(got, iv.Start.In(loc).Format("01-02 15:04 -0700"))
+				if iv.Duration() != time.Hour {
+					t.Errorf("occurrence at %v lasts %s, want 1h", iv.Start, iv.Duration())
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRRule_UntilIn(t *testing.T) {
+	moscow, err := time.LoadLocation("Europe/Moscow")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+
+	tests := []struct {
+		until string
+		want  time.Time
+	}{
+		{"20250310", time.Date(2025, 3, 10, 23, 59, 59, 999999999, moscow)},
+		{"20250310T120000", time.Date(2025, 3, 10, 12, 0, 0, 0, moscow)},
+		{"20250310T120000Z", time.Date(2025, 3, 10, 12, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		r, err := recur.ParseRRule("FREQ=DAILY;UNTIL=" + tt.until)
+		if err != nil {
+			t.Fatalf("ParseRRule(%s) error = %v", tt.until, err)
+		}
+		if got := r.UntilIn(moscow); !got.Equal(tt.want) {
+			t.Errorf("UntilIn() for %s = %v, want %v", tt.until, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,137 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestMockClient_ShiftReviewSlots(t *testing.T) {
+	base := slotBase()
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	free := func(from, to int) *mockSlot { return &mockSlot{start: at(from), end: at(to)} }
+
+	tests := []struct {
+		name     string
+		slots    map[string]*mockSlot
+		from, to int
+		by       time.Duration
+		moved    map[string]int // slot ID -> new start hour
+		collided []string
+	}{
+		{"adjacent slots move later together",
+			map[string]*mockSlot{"s1": free(0, 1), "s2": free(1, 2)},
+			0, 2, time.Hour, map[string]int{"s1": 1, "s2": 2}, nil},
+		{"adjacent slots move earlier together",
+			map[string]*mockSlot{"s1": free(2, 3), "s2": free(3, 4)},
+			0, 4, -time.Hour, map[string]int{"s1": 1, "s2": 2}, nil},
+		{"slot outside the range stays and blocks",
+			map[string]*mockSlot{"s1": free(0, 1), "s2": free(1, 2)},
+			0, 1, time.Hour, nil, []string{"s1"}},
+		{"booked slot blocks, and so does the slot stuck behind it",
+			map[string]*mockSlot{"s1": free(0, 1), "s2": free(1, 2), "b1": {start: at(2), end: at(3), booked: true}},
+			0, 2, time.Hour, nil, []string{"s1", "s2"}},
+		{"zero offset moves nothing",
+			map[string]*mockSlot{"s1": free(0, 1)},
+			0, 1, 0, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			starts := make(map[string]time.Time)
+			for id, s := range tt.slots {
+				starts[id] = s.start
+			}
+			ss := newSlotServer(tt.slots)
+			c := newSlotClient(t, ss)
+
+			results, err := c.ShiftReviewSlots(context.Background(), at(tt.from), at(tt.to), tt.by)
+			if err != nil {
+				t.Fatalf("ShiftReviewSlots() error = %v", err)
+			}
+			for i := 1; i < len(results); i++ {
+				if results[i].Slot.Start.Before(results[i-1].Slot.Start) {
+					t.Errorf("results not sorted by original start: %+v", results)
+				}
+			}
+
+			var collided []string
+			for _, r := range results {
+				switch {
+				case errors.Is(r.Err, client.ErrCollision):
+					collided = append(collided, r.Slot.ID)
+				case r.Err != nil:
+					t.Errorf("slot %s error = %v", r.Slot.ID, r.Err)
+				case r.Moved == nil && tt.by != 0:
+					t.Errorf("slot %s neither moved nor failed", r.Slot.ID)
+				}
+			}
+			if len(collided) != len(tt.collided) {
+				t.Fatalf("collided = %v, want %v", collided, tt.collided)
+			}
+			for i := range collided {
+				if collided[i] != tt.collided[i] {
+					t.Errorf("collided = %v, want %v", collided, tt.collided)
+				}
+			}
+
+			for id, want := range starts {
+				if h, ok := tt.moved[id]; ok {
+					want = at(h)
+				}
+				if got, _ := ss.slot(id); !got.start.Equal(want) {
+					t.Errorf("slot %s starts at %v, want %v", id, got.start, want)
+				}
+			}
+		})
+	}
+}
+
+func TestMockClient_ShiftReviewSlotsFollowsConflictPolicy(t *testing.T) {
+	base := slotBase()
+	at := func(h int) time.Time { return base.Add(time.Duration(h) * time.Hour) }
+	event := func(id string, from, to int, kind string, details map[string]interface{}) map[string]interface{} {
+		return map[string]interface{}{"id": id, "start": at(from).Format(time.RFC3339), "end": at(to).Format(time.RFC3339), kind: details}
+	}
+
+	tests := []struct {
+		name    string
+		exam    client.ConflictAction
+		wantErr bool
+	}{
+		{"exam blocks by default", client.ConflictBlock, true},
+		{"exam set to warn lets the slot through", client.ConflictWarn, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ss := newSlotServer(map[string]*mockSlot{"s1": {start: at(0), end: at(1)}, "s2": {start: at(4), end: at(5)}})
+			ss.events = []map[string]interface{}{
+				event("exam-1", 2, 3, "exam", map[string]interface{}{"name": "Exam"}),
+				event("activity-1", 6, 7, "activity", map[string]interface{}{"name": "Lecture", "isRegistered": false}),
+			}
+			c := newSlotClient(t, ss)
+			c.SetConflictAction(client.EventExam, tt.exam)
+
+			results, err := c.ShiftReviewSlots(context.Background(), at(0), at(5), 2*time.Hour)
+			if err != nil {
+				t.Fatalf("ShiftReviewSlots() error = %v", err)
+			}
+			if len(results) != 2 {
+				t.Fatalf("ShiftReviewSlots() = %+v, want both slots", results)
+			}
+			if got := errors.Is(results[0].Err, client.ErrCollision); got != tt.wantErr {
+				t.Errorf("s1 error = %v, want a collision %v", results[0].Err, tt.wantErr)
+			}
+			if results[1].Err != nil {
+				t.Errorf("s2 onto an activity we are not registered for error = %v", results[1].Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,167 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+type mockSlot struct {
+	start, end time.Time
+	booked     bool
+	verifier   string // login of the verifier of a booked slot, listed as a booking if set
+}
+
+// slotServer keeps a calendar of review slots that the slot mutations change
+type slotServer struct {
+	mu         sync.Mutex
+	slots      map[string]*mockSlot
+	created    int
+	adds       int
+	failAddAt  int                      // fail the add with this 1-based number, 0 never
+	failChange bool                     // fail every change of slot times
+	failGet    bool                     // fail every calendar fetch
+	events     []map[string]interface{} // other calendar events, returned as they are
+}
+
+func newSlotServer(slots map[string]*mockSlot) *slotServer {
+	return &slotServer{slots: slots}
+}
+
+func (ss *slotServer) slot(id string) (mockSlot, bool) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	s, ok := ss.slots[id]
+	if !ok {
+		return mockSlot{}, false
+	}
+	return *s, true
+}
+
+func (ss *slotServer) book(id string) {
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+	ss.slots[id].booked = true
+}
+
+func slotJSON(id string, s *mockSlot) map[string]interface{} {
+	typ := "FREE_TIME"
+	if s.booked {
+		typ = "BOOKED_TIME"
+	}
+	return map[string]interface{}{
+		"id":    id,
+		"type":  typ,
+		"start": s.start.Format(time.RFC3339),
+		"end":   s.end.Format(time.RFC3339),
+	}
+}
+
+func reviewEvent(slots ...map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"id":         "event-review",
+		"eventType":  "Я проверяю",
+		"eventCode":  "student_check",
+		"eventSlots": slots,
+	}
+}
+
+func (ss *slotServer) handle(w http.ResponseWriter, r *http.Request) {
+	var req client.GraphQLRequest
+	json.NewDecoder(r.Body).Decode(&req)
+	parse := func(name string) time.Time {
+		t, _ := time.Parse(time.RFC3339, req.Variables[name].(string))
+		return t
+	}
+
+	ss.mu.Lock()
+	defer ss.mu.Unlock()
+
+	var data map[string]interface{}
+	switch req.OperationName {
+	case "calendarGetEvents":
+		if ss.failGet {
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]interface{}{{"message": "fetch failed"}}})
+			return
+		}
+		from, to := parse("from"), parse("to")
+		var slots, bookings []map[string]interface{}
+		for id, s := range ss.slots {
+			if s.start.Before(to) && s.end.After(from) {
+				slots = append(slots, slotJSON(id, s))
+				if s.booked && s.verifier != "" {
+					bookings = append(bookings, map[string]interface{}{
+						"id":           "booking-" + id,
+						"eventSlot":    slotJSON(id, s),
+						"verifierUser": map[string]interface{}{"login": s.verifier},
+					})
+				}
+			}
+		}
+		events := append([]map[string]interface{}{}, ss.events...)
+		if len(slots) > 0 {
+			event := reviewEvent(slots...)
+			event["bookings"] = bookings
+			events = append(events, event)
+		}
+		data = map[string]interface{}{"calendarEventS21": map[string]interface{}{"getMyCalendarEvents": events}}
+	case "calendarAddEvent":
+		ss.adds++
+		if ss.adds == ss.failAddAt {
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]interface{}{{"message": "add failed"}}})
+			return
+		}
+		ss.created++
+		id := fmt.Sprintf("slot-new-%d", ss.created)
+		ss.slots[id] = &mockSlot{start: parse("start"), end: parse("end")}
+		data = map[string]interface{}{"student": map[string]interface{}{
+			"addEventToTimetable": []map[string]interface{}{reviewEvent(slotJSON(id, ss.slots[id]))},
+		}}
+	case "calendarChangeEventSlot":
+		if ss.failChange {
+			json.NewEncoder(w).Encode(map[string]interface{}{"errors": []map[string]interface{}{{"message": "change failed"}}})
+			return
+		}
+		id := req.Variables["id"].(string)
+		s := ss.slots[id]
+		s.start, s.end = parse("start"), parse("end")
+		data = map[string]interface{}{"student": map[string]interface{}{"changeEventSlot": reviewEvent(slotJSON(id, s))}}
+	case "calendarDeleteEventSlot":
+		delete(ss.slots, req.Variables["eventSlotId"].(string))
+		data = map[string]interface{}{"student": map[string]interface{}{"deleteEventSlot": true}}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+}
+
+func newSlotClient(t *testing.T, ss *slotServer, opts ...client.ClientOption) *client.Client {
+	t.Helper()
+	tokenResp := &client.TokenResponse{AccessToken: "mock-token", TokenType: "Bearer", ExpiresIn: 3600}
+
+	authServer := mockAuthServer(tokenResp)
+	t.Cleanup(authServer.Close)
+	graphqlServer := mockGraphQLServer(ss.handle)
+	t.Cleanup(graphqlServer.Close)
+
+	opts = append([]client.ClientOption{
+		client.WithAuthURL(authServer.URL),
+		client.WithBaseURL(graphqlServer.URL),
+		client.WithoutSlotValidation(),
+	}, opts...)
+	c := client.NewClient(&client.AuthConfig{Login: "test@example.com", Password: "testpass"}, opts...)
+	c.SetToken(tokenResp, time.Now().Add(time.Hour))
+	return c
+}
+
+func slotBase() time.Time {
+	return time.Now().UTC().Truncate(time.Hour).Add(48 * time.Hour)
+}
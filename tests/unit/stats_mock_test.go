@@ -0,0 +1,95 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/stats"
+)
+
+func TestAnalyze(t *testing.T) {
+	// Monday March 3 to Monday March 17, ISO weeks 10 and 11
+	from := time.Date(2025, 3, 3, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 0, 14)
+	at := func(day, hour, min int) time.Time { return time.Date(2025, 3, day, hour, min, 0, 0, time.UTC) }
+	people := func(logins ...string) []client.Participant {
+		var list []client.Participant
+		for _, l := range logins {
+			list = append(list, client.Participant{Login: l})
+		}
+		return list
+	}
+	booking := func(id, project string, role client.Role, start time.Time, minutes int) client.ReviewBooking {
+		return client.ReviewBooking{ID: id, ProjectName: project, Role: role, Start: start, End: start.Add(time.Duration(minutes) * time.Minute)}
+	}
+	bucket := func(key string, reviews, minutes int) stats.Bucket {
+		return stats.Bucket{Key: key, Reviews: reviews, Minutes: minutes}
+	}
+
+	b1 := booking("b1", "C2", client.RoleVerifier, at(3, 14, 0), 30)
+	b1.Reviewees = people("alice")
+	b2 := booking("b2", "C2", client.RoleVerifier, at(4, 14, 0), 60)
+	b2.Team = people("bob", "alice") // no reviewees listed
+	b3 := booking("b3", "D01", client.RoleVerifiable, at(11, 9, 0), 45)
+	b3.Reviewees = people("carol")
+	b4 := booking("b4", "", client.RoleVerifier, at(12, 10, 0), 30)
+	b4.Reviewees = people("bob", "")
+	bookings := []client.ReviewBooking{
+		b1, b2, b2, b3, b4, // b2 listed twice
+		booking("early", "C2", client.RoleVerifier, at(2, 14, 0), 30),
+		booking("late", "C2", client.RoleVerifier, at(17, 0, 0), 30),
+	}
+	cancelled := []client.ReviewBooking{
+		booking("c1", "C3", client.RoleVerifier, at(5, 12, 0), 30),
+		booking("c1", "C3", client.RoleVerifier, at(5, 12, 0), 30),
+		b1, // cancelled and booked again, so it took place
+		booking("c2", "C3", client.RoleVerifier, at(20, 12, 0), 30),
+	}
+
+	r := stats.Analyze(bookings, cancelled, from, to, stats.Options{Location: time.UTC, Top: 2})
+	if r.Reviews != 4 || r.Cancelled != 1 || r.TotalMinutes != 165 || r.TotalTime() != 165*time.Minute {
+		t.Errorf("Analyze() reviews = %d, cancelled = %d, minutes = %d; want 4, 1, 165", r.Reviews, r.Cancelled, r.TotalMinutes)
+	}
+	if r.CancellationRate != 0.2 {
+		t.Errorf("CancellationRate = %v, want 0.2", r.CancellationRate)
+	}
+	buckets := []struct {
+		name string
+		got  []stats.Bucket
+		want []stats.Bucket
+	}{
+		{"ByProject", r.ByProject, []stats.Bucket{bucket("C2", 2, 90), bucket("D01", 1, 45), bucket("unknown", 1, 30)}},
+		{"ByWeek", r.ByWeek, []stats.Bucket{bucket("2025-W10", 2, 90), bucket("2025-W11", 2, 75)}},
+		{"ByHour", r.ByHour, []stats.Bucket{bucket("09:00", 1, 45), bucket("10:00", 1, 30), bucket("14:00", 2, 90)}},
+		{"TopReviewees", r.TopReviewees, []stats.Bucket{bucket("alice", 2, 90), bucket("bob", 2, 90)}},
+	}
+	for _, b := range buckets {
+		if !reflect.DeepEqual(b.got, b.want) {
+			t.Errorf("%s = %+v, want %+v", b.name, b.got, b.want)
+		}
+	}
+
+	// Roles filter cancellations too, and hours follow the location
+	r = stats.Analyze(bookings, cancelled, from, to, stats.Options{
+		Roles:    []client.Role{client.RoleVerifiable},
+		Location: time.FixedZone("UTC+3", 3*60*60),
+	})
+	if r.Reviews != 1 || r.Cancelled != 0 || r.CancellationRate != 0 {
+		t.Errorf("Analyze() for verifiable reviews = %d, cancelled = %d; want 1, 0", r.Reviews, r.Cancelled)
+	}
+	if want := []stats.Bucket{bucket("12:00", 1, 45)}; !reflect.DeepEqual(r.ByHour, want) {
+		t.Errorf("ByHour = %+v, want %+v", r.ByHour, want)
+	}
+
+	// A Sunday evening in UTC is already Monday of the next week in UTC+3
+	late := booking("late", "C2", client.RoleVerifier, at(9, 22, 0), 30)
+	r = stats.Analyze([]client.ReviewBooking{late}, nil, from, to, stats.Options{Location: time.FixedZone("UTC+3", 3*60*60)})
+	if want := []stats.Bucket{bucket("2025-W11", 1, 30)}; !reflect.DeepEqual(r.ByWeek, want) {
+		t.Errorf("ByWeek = %+v, want %+v", r.ByWeek, want)
+	}
+}
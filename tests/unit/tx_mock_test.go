@@ -0,0 +1,137 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestMockClient_TransactionRollsBackOnFailure(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+		"slot-2": {start: base.Add(5 * time.Hour), end: base.Add(6 * time.Hour)},
+	})
+	ss.failAddAt = 2
+	c := newSlotClient(t, ss)
+	ctx := context.Background()
+
+	report, err := c.Transaction(ctx, func(tx *client.Tx) error {
+		if _, err := tx.AddReviewSlot(ctx, base.Add(2*time.Hour), base.Add(3*time.Hour)); err != nil {
+			return err
+		}
+		if _, err := tx.UpdateReviewSlot(ctx, "slot-1", base.Add(30*time.Minute), base.Add(90*time.Minute)); err != nil {
+			return err
+		}
+		if err := tx.RemoveReviewSlot(ctx, "slot-2"); err != nil {
+			return err
+		}
+		_, err := tx.AddReviewSlot(ctx, base.Add(8*time.Hour), base.Add(9*time.Hour))
+		return err
+	})
+	if err == nil {
+		t.Fatal("Transaction() error = nil, want the failed add")
+	}
+	if report == nil || report.Cause != err {
+		t.Fatalf("Transaction() report = %+v, want one caused by %v", report, err)
+	}
+	if len(report.Applied) != 3 || len(report.Compensations) != 3 {
+		t.Fatalf("Report has %d steps and %d compensations, want 3 and 3", len(report.Applied), len(report.Compensations))
+	}
+	if !report.Complete() {
+		t.Errorf("Rollback incomplete: %+v", report.Failed())
+	}
+	wantOps := []client.MutationOp{client.MutationRemove, client.MutationUpdate, client.MutationAdd}
+	for i, comp := range report.Compensations {
+		if comp.Step.Op != wantOps[i] {
+			t.Errorf("Compensation %d undoes %s, want %s", i, comp.Step.Op, wantOps[i])
+		}
+	}
+
+	if _, ok := ss.slot("slot-new-1"); ok {
+		t.Error("Added slot still exists after rollback")
+	}
+	s1, _ := ss.slot("slot-1")
+	if !s1.start.Equal(base) || !s1.end.Equal(base.Add(time.Hour)) {
+		t.Errorf("slot-1 = %s - %s after rollback, want its original times", s1.start, s1.end)
+	}
+	restored, ok := ss.slot("slot-new-2")
+	if !ok || !restored.start.Equal(base.Add(5*time.Hour)) {
+		t.Errorf("Removed slot-2 was not recreated at its original times: %+v", restored)
+	}
+	if len(ss.slots) != 2 {
+		t.Errorf("Calendar has %d slots after rollback, want 2", len(ss.slots))
+	}
+}
+
+func TestMockClient_TransactionRollbackLeavesBookedSlots(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	ctx := context.Background()
+
+	tx := c.Begin()
+	if _, err := tx.AddReviewSlot(ctx, base.Add(2*time.Hour), base.Add(3*time.Hour)); err != nil {
+		t.Fatalf("AddReviewSlot() error = %v", err)
+	}
+	if _, err := tx.UpdateReviewSlot(ctx, "slot-1", base.Add(4*time.Hour), base.Add(5*time.Hour)); err != nil {
+		t.Fatalf("UpdateReviewSlot() error = %v", err)
+	}
+	ss.book("slot-new-1")
+
+	report := tx.Rollback(ctx)
+	if report.Complete() {
+		t.Fatal("Rollback complete, want the booked slot left alone")
+	}
+	failed := report.Failed()
+	if len(failed) != 1 || failed[0].Step.Op != client.MutationAdd || !errors.Is(failed[0].Err, client.ErrSlotBooked) {
+		t.Errorf("Failed compensations = %+v, want the add refused with ErrSlotBooked", failed)
+	}
+	if _, ok := ss.slot("slot-new-1"); !ok {
+		t.Error("Booked slot was removed by the rollback")
+	}
+	if s1, _ := ss.slot("slot-1"); !s1.start.Equal(base) {
+		t.Errorf("slot-1 starts at %s after rollback, want %s", s1.start, base)
+	}
+
+	if _, err := tx.AddReviewSlot(ctx, base, base.Add(time.Hour)); !errors.Is(err, client.ErrTxDone) {
+		t.Errorf("AddReviewSlot() after rollback error = %v, want ErrTxDone", err)
+	}
+	if again := tx.Rollback(ctx); len(again.Compensations) != 0 {
+		t.Errorf("Second rollback ran %d compensations, want 0", len(again.Compensations))
+	}
+}
+
+func TestMockClient_TransactionRefusesBookedSlots(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour), booked: true},
+	})
+	c := newSlotClient(t, ss)
+
+	tx := c.Begin()
+	if err := tx.RemoveReviewSlot(context.Background(), "slot-1"); !errors.Is(err, client.ErrSlotBooked) {
+		t.Errorf("RemoveReviewSlot() of a booked slot error = %v, want ErrSlotBooked", err)
+	}
+	if _, err := tx.UpdateReviewSlot(context.Background(), "slot-1", base.Add(2*time.Hour), base.Add(3*time.Hour)); !errors.Is(err, client.ErrSlotBooked) {
+		t.Errorf("UpdateReviewSlot() of a booked slot error = %v, want ErrSlotBooked", err)
+	}
+	if s1, _ := ss.slot("slot-1"); !s1.start.Equal(base) {
+		t.Errorf("Booked slot moved to %s", s1.start)
+	}
+	if _, ok := ss.slot("slot-1"); !ok {
+		t.Error("Booked slot was removed")
+	}
+	tx.Commit()
+	if len(tx.Steps()) != 0 {
+		t.Errorf("Tx recorded %d steps, want 0", len(tx.Steps()))
+	}
+}
@@ -0,0 +1,233 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+	"github.com/arseniisemenow/s21gql/pkg/journal"
+)
+
+// memJournal collects the mutations of a client like the CLI journal does
+type memJournal struct {
+	mu      sync.Mutex
+	entries []journal.Entry
+}
+
+// record sets the client to journal its mutations, as undos of undoes if set
+func (j *memJournal) record(c *client.Client, undoes string) {
+	c.SetMutationObserver(func(m client.Mutation) {
+		e := journal.MutationEntry(m)
+		e.Undoes = undoes
+		j.mu.Lock()
+		j.entries = append(j.entries, e)
+		j.mu.Unlock()
+	})
+}
+
+// undo undoes the n newest undoable mutations with a fresh undoer, as one
+// run of the undo command does
+func (j *memJournal) undo(t *testing.T, c *client.Client, n int) error {
+	t.Helper()
+	undoer := journal.NewUndoer(c, j.entries)
+	for _, e := range journal.Undoable(j.entries, n) {
+		j.record(c, e.Key())
+		key := e.Key()
+		undoer.Gone = func(s client.ReviewSlot) {
+			j.mu.Lock()
+			j.entries = append(j.entries, journal.GoneEntry(s, key, time.Now()))
+			j.mu.Unlock()
+		}
+		if err := undoer.Undo(context.Background(), *e.Mutation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestJournal_Undoable(t *testing.T) {
+	at := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	slot := func(id string) client.ReviewSlot {
+		return client.ReviewSlot{ID: id, Start: at, End: at.Add(time.Hour), Type: client.SlotTypeFree}
+	}
+	mutation := func(sec int, op client.MutationOp, id string, after ...client.ReviewSlot) journal.Entry {
+		return journal.MutationEntry(client.Mutation{Op: op, SlotID: id, After: after, At: at.Add(time.Duration(sec) * time.Second)})
+	}
+	undo := func(e journal.Entry, sec int, op client.MutationOp, id string) journal.Entry {
+		u := mutation(sec, op, id)
+		u.Undoes = e.Key()
+		return u
+	}
+
+	add := mutation(1, client.MutationAdd, "", slot("a"), slot("b"))
+	update := mutation(2, client.MutationUpdate, "c", slot("c"))
+	remove := mutation(3, client.MutationRemove, "d")
+	failed := mutation(4, client.MutationRemove, "e")
+	failed.Mutation.Error = "rejected"
+	single := mutation(5, client.MutationAdd, "", slot("f"))
+
+	tests := []struct {
+		name    string
+		entries []journal.Entry
+		n       int
+		want    []journal.Entry
+	}{
+		{"newest first, failed left out", []journal.Entry{add, update, remove, failed, single}, 10,
+			[]journal.Entry{single, remove, update, add}},
+		{"at most n", []journal.Entry{add, update, remove, failed, single}, 2,
+			[]journal.Entry{single, remove}},
+		{"undone and undos left out", []journal.Entry{add, update, remove, undo(remove, 6, client.MutationAdd, ""),
+			single, undo(single, 7, client.MutationRemove, "f")}, 10,
+			[]journal.Entry{update, add}},
+		{"add undone halfway stays undoable", []journal.Entry{add, undo(add, 6, client.MutationRemove, "a")}, 10,
+			[]journal.Entry{add}},
+		{"add undone fully", []journal.Entry{add, undo(add, 6, client.MutationRemove, "a"),
+			undo(add, 7, client.MutationRemove, "b")}, 10, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := journal.Undoable(tt.entries, tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Undoable() returned %d entries, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i].Key() != tt.want[i].Key() {
+					t.Errorf("entry %d = %s, want %s", i, got[i].Key(), tt.want[i].Key())
+				}
+			}
+		})
+	}
+}
+
+func TestMockUndoer_FollowsRecreatedSlots(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{})
+	c := newSlotClient(t, ss)
+	ctx := context.Background()
+	j := &memJournal{}
+	j.record(c, "")
+
+	slots, err := c.AddReviewSlot(ctx, base, base.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("AddReviewSlot() error = %v", err)
+	}
+	id := slots[0].ID
+	if _, err := c.UpdateReviewSlot(ctx, id, base.Add(2*time.Hour), base.Add(3*time.Hour)); err != nil {
+		t.Fatalf("UpdateReviewSlot() error = %v", err)
+	}
+	if err := c.RemoveReviewSlot(ctx, id); err != nil {
+		t.Fatalf("RemoveReviewSlot() error = %v", err)
+	}
+
+	// Undoing the remove recreates the slot under a new ID; the next run
+	// must move and then remove that one
+	if err := j.undo(t, c, 1); err != nil {
+		t.Fatalf("undo of the remove error = %v", err)
+	}
+	recreated, ok := ss.slot("slot-new-2")
+	if !ok || !recreated.start.Equal(base.Add(2*time.Hour)) {
+		t.Fatalf("slot after undoing the remove = %+v, want slot-new-2 at its last times", ss.slots)
+	}
+
+	if err := j.undo(t, c, 1); err != nil {
+		t.Fatalf("undo of the update error = %v", err)
+	}
+	if moved, _ := ss.slot("slot-new-2"); !moved.start.Equal(base) {
+		t.Errorf("slot-new-2 starts at %v after undoing the update, want %v", moved.start, base)
+	}
+
+	if err := j.undo(t, c, 1); err != nil {
+		t.Fatalf("undo of the add error = %v", err)
+	}
+	if len(ss.slots) != 0 {
+		t.Errorf("slots left after undoing everything: %v", ss.slots)
+	}
+	if left := journal.Undoable(j.entries, 10); len(left) != 0 {
+		t.Errorf("Undoable() after undoing everything = %d entries, want none", len(left))
+	}
+}
+
+func TestMockUndoer_PartialAddReportsRemovedSlots(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+		"slot-2": {start: base.Add(time.Hour), end: base.Add(2 * time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	j := &memJournal{}
+
+	add := client.Mutation{Op: client.MutationAdd, At: base.Add(-time.Hour), After: []client.ReviewSlot{
+		{ID: "slot-1", Start: base, End: base.Add(time.Hour), Type: client.SlotTypeFree},
+		{ID: "slot-2", Start: base.Add(time.Hour), End: base.Add(2 * time.Hour), Type: client.SlotTypeFree},
+	}}
+	j.entries = append(j.entries, journal.MutationEntry(add))
+	ss.book("slot-2")
+
+	err := j.undo(t, c, 1)
+	if !errors.Is(err, client.ErrSlotBooked) || !strings.Contains(err.Error(), "removed slot(s) slot-1") {
+		t.Fatalf("undo error = %v, want ErrSlotBooked naming the removed slot-1", err)
+	}
+	if _, ok := ss.slot("slot-1"); ok {
+		t.Error("slot-1 still exists")
+	}
+	if left := journal.Undoable(j.entries, 10); len(left) != 1 {
+		t.Fatalf("Undoable() = %d entries, want the half-undone add", len(left))
+	}
+
+	// Once the booking is gone, undoing again removes the rest
+	ss.mu.Lock()
+	ss.slots["slot-2"].booked = false
+	ss.mu.Unlock()
+	if err := j.undo(t, c, 1); err != nil {
+		t.Fatalf("second undo error = %v", err)
+	}
+	if len(ss.slots) != 0 {
+		t.Errorf("slots left: %v", ss.slots)
+	}
+	if left := journal.Undoable(j.entries, 10); len(left) != 0 {
+		t.Errorf("Undoable() = %d entries, want none", len(left))
+	}
+}
+
+func TestMockUndoer_AddOfRemovedSlotCountsAsUndone(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-2": {start: base.Add(time.Hour), end: base.Add(2 * time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	j := &memJournal{}
+
+	update := client.Mutation{Op: client.MutationUpdate, SlotID: "slot-2", At: base.Add(-2 * time.Hour),
+		Before: &client.ReviewSlot{ID: "slot-2", Start: base.Add(3 * time.Hour), End: base.Add(4 * time.Hour), Type: client.SlotTypeFree},
+		After:  []client.ReviewSlot{{ID: "slot-2", Start: base.Add(time.Hour), End: base.Add(2 * time.Hour), Type: client.SlotTypeFree}}}
+	add := client.Mutation{Op: client.MutationAdd, At: base.Add(-time.Hour), After: []client.ReviewSlot{
+		{ID: "slot-1", Start: base, End: base.Add(time.Hour), Type: client.SlotTypeFree},
+	}}
+	j.entries = append(j.entries, journal.MutationEntry(update), journal.MutationEntry(add))
+
+	// slot-1 was removed some other way since, so there is nothing to remove
+	if err := journal.NewUndoer(c, j.entries).Undo(context.Background(), add); !errors.Is(err, client.ErrSlotNotFound) {
+		t.Fatalf("Undo() without Gone error = %v, want ErrSlotNotFound", err)
+	}
+	if err := j.undo(t, c, 1); err != nil {
+		t.Fatalf("undo error = %v", err)
+	}
+	left := journal.Undoable(j.entries, 10)
+	if len(left) != 1 || left[0].Mutation.Op != client.MutationUpdate {
+		t.Fatalf("Undoable() = %+v, want only the update left", left)
+	}
+	if err := j.undo(t, c, 1); err != nil {
+		t.Fatalf("second undo error = %v", err)
+	}
+	if s, _ := ss.slot("slot-2"); !s.start.Equal(base.Add(3 * time.Hour)) {
+		t.Errorf("slot-2 starts at %v, want it moved back", s.start)
+	}
+}
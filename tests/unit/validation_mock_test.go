@@ -0,0 +1,68 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestSlotRules_CheckBoundaries(t *testing.T) {
+	now := time.Date(2025, 3, 1, 10, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) time.Time { return now.Add(d) }
+	existing := []client.ReviewSlot{
+		{ID: "slot-1", Start: at(5 * time.Hour), End: at(6 * time.Hour), Type: client.SlotTypeFree},
+	}
+
+	tests := []struct {
+		name  string
+		rules client.SlotRules
+		start time.Time
+		end   time.Time
+		want  []client.SlotRule
+	}{
+		{"exactly the lead time", client.DefaultSlotRules, at(30 * time.Minute), at(time.Hour), nil},
+		{"inside the lead time", client.DefaultSlotRules, at(15 * time.Minute), at(time.Hour),
+			[]client.SlotRule{client.RuleLeadTime}},
+		{"exactly the minimum duration", client.DefaultSlotRules, at(time.Hour), at(90 * time.Minute), nil},
+		{"below the minimum duration", client.DefaultSlotRules, at(time.Hour), at(75 * time.Minute),
+			[]client.SlotRule{client.RuleMinDuration}},
+		{"end before start", client.DefaultSlotRules, at(2 * time.Hour), at(time.Hour),
+			[]client.SlotRule{client.RuleMinDuration}},
+		{"exactly the maximum duration", client.DefaultSlotRules, at(6 * time.Hour), at(18 * time.Hour), nil},
+		{"above the maximum duration", client.DefaultSlotRules, at(6 * time.Hour), at(18*time.Hour + 15*time.Minute),
+			[]client.SlotRule{client.RuleMaxDuration}},
+		{"start off the step", client.DefaultSlotRules, at(time.Hour + 5*time.Minute), at(2 * time.Hour),
+			[]client.SlotRule{client.RuleGranularity}},
+		{"end off the step", client.DefaultSlotRules, at(time.Hour), at(2*time.Hour + 10*time.Minute),
+			[]client.SlotRule{client.RuleGranularity}},
+		{"ends exactly at the horizon", client.DefaultSlotRules, at(14*24*time.Hour - time.Hour), at(14 * 24 * time.Hour), nil},
+		{"ends past the horizon", client.DefaultSlotRules, at(14*24*time.Hour - time.Hour), at(14*24*time.Hour + 15*time.Minute),
+			[]client.SlotRule{client.RuleMaxHorizon}},
+		{"touches an existing slot", client.DefaultSlotRules, at(4 * time.Hour), at(5 * time.Hour), nil},
+		{"overlaps an existing slot", client.DefaultSlotRules, at(4 * time.Hour), at(5*time.Hour + 15*time.Minute),
+			[]client.SlotRule{client.RuleOverlap}},
+		{"several rules at once", client.DefaultSlotRules, at(5 * time.Minute), at(20 * time.Minute),
+			[]client.SlotRule{client.RuleMinDuration, client.RuleGranularity, client.RuleLeadTime}},
+		{"zero rules only check shape and overlap", client.SlotRules{}, at(5 * time.Minute), at(7 * time.Minute), nil},
+		{"zero rules still refuse an overlap", client.SlotRules{}, at(5*time.Hour + 5*time.Minute), at(7 * time.Hour),
+			[]client.SlotRule{client.RuleOverlap}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violations := tt.rules.Check(client.Interval{Start: tt.start, End: tt.end}, existing, now)
+			if len(violations) != len(tt.want) {
+				t.Fatalf("Check() = %+v, want %v", violations, tt.want)
+			}
+			for i, v := range violations {
+				if v.Rule != tt.want[i] {
+					t.Errorf("violation %d = %s, want %s", i, v.Rule, tt.want[i])
+				}
+			}
+		})
+	}
+}
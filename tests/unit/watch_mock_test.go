@@ -0,0 +1,194 @@
+//go:build mock
+// +build mock
+
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/arseniisemenow/s21gql/pkg/client"
+)
+
+func TestDiffCalendar(t *testing.T) {
+	base := slotBase()
+	hour := client.Interval{Start: base, End: base.Add(time.Hour)}
+	elsewhere, err := time.LoadLocation("Asia/Novosibirsk")
+	if err != nil {
+		t.Skipf("no tzdata: %v", err)
+	}
+	sameHour := client.Interval{Start: base.In(elsewhere), End: base.Add(time.Hour).In(elsewhere)}
+	exam := client.BusyEvent{Interval: hour, Kind: client.EventExam, ID: "e1", Title: "Exam"}
+	slot := client.ReviewSlot{ID: "s1", Start: hour.Start, End: hour.End, Type: client.SlotTypeFree}
+
+	snapshot := func(slots []client.ReviewSlot, events ...client.BusyEvent) *client.CalendarSnapshot {
+		s := &client.CalendarSnapshot{
+			TakenAt:  base,
+			Slots:    make(map[string]client.ReviewSlot),
+			Bookings: make(map[string]client.ReviewBooking),
+			Events:   make(map[string]client.BusyEvent),
+		}
+		for _, slot := range slots {
+			s.Slots[slot.ID] = slot
+		}
+		for _, e := range events {
+			s.Events[e.ID] = e
+		}
+		return s
+	}
+	with := func(e client.BusyEvent, f func(*client.BusyEvent)) client.BusyEvent {
+		f(&e)
+		return e
+	}
+	booked := slot
+	booked.Type = "BOOKED_TIME"
+	moved := slot
+	moved.End = moved.End.Add(30 * time.Minute)
+
+	tests := []struct {
+		name string
+		prev *client.CalendarSnapshot
+		cur  *client.CalendarSnapshot
+		want []client.ChangeType
+	}{
+		{"nothing changed", snapshot(nil, exam), snapshot(nil, exam), nil},
+		{"same instant in another zone", snapshot(nil, exam),
+			snapshot(nil, with(exam, func(e *client.BusyEvent) { e.Interval = sameHour })), nil},
+		{"exam moved", snapshot(nil, exam),
+			snapshot(nil, with(exam, func(e *client.BusyEvent) { e.End = e.End.Add(time.Hour) })),
+			[]client.ChangeType{client.ExamChanged}},
+		{"event renamed", snapshot(nil, with(exam, func(e *client.BusyEvent) { e.Kind = client.EventActivity })),
+			snapshot(nil, with(exam, func(e *client.BusyEvent) { e.Kind = client.EventActivity; e.Title = "Talk" })),
+			[]client.ChangeType{client.EventChanged}},
+		{"exam added and removed", snapshot(nil, exam),
+			snapshot(nil, with(exam, func(e *client.BusyEvent) { e.ID = "e2" })),
+			[]client.ChangeType{client.ExamAdded, client.ExamRemoved}},
+		{"slot added", snapshot(nil), snapshot([]client.ReviewSlot{slot}), []client.ChangeType{client.SlotAdded}},
+		{"slot removed", snapshot([]client.ReviewSlot{slot}), snapshot(nil), []client.ChangeType{client.SlotRemoved}},
+		{"slot booked", snapshot([]client.ReviewSlot{slot}), snapshot([]client.ReviewSlot{booked}),
+			[]client.ChangeType{client.SlotBooked}},
+		{"slot resized", snapshot([]client.ReviewSlot{slot}), snapshot([]client.ReviewSlot{moved}),
+			[]client.ChangeType{client.SlotResized}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			changes := client.DiffCalendar(tt.prev, tt.cur)
+			if len(changes) != len(tt.want) {
+				t.Fatalf("DiffCalendar() = %+v, want %v", changes, tt.want)
+			}
+			for i, ch := range changes {
+				if ch.Type != tt.want[i] {
+					t.Errorf("change %d = %s, want %s", i, ch.Type, tt.want[i])
+				}
+				if !ch.DetectedAt.Equal(base) {
+					t.Errorf("change %d DetectedAt = %v, want %v", i, ch.DetectedAt, base)
+				}
+			}
+		})
+	}
+}
+
+// nextChange returns the next change from a watch, failing after a timeout
+func nextChange(t *testing.T, changes <-chan client.CalendarChange) client.CalendarChange {
+	t.Helper()
+	select {
+	case ch, ok := <-changes:
+		if !ok {
+			t.Fatal("watch channel closed early")
+		}
+		return ch
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change within 5s")
+	}
+	return client.CalendarChange{}
+}
+
+func TestMockClient_WatchCalendar(t *testing.T) {
+	base := slotBase()
+	ss := newSlotServer(map[string]*mockSlot{
+		"slot-1": {start: base, end: base.Add(time.Hour)},
+	})
+	c := newSlotClient(t, ss)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := c.WatchCalendar(ctx, base.Add(-time.Hour), base.Add(48*time.Hour), 20*time.Millisecond)
+
+	// Let the baseline poll happen before changing anything
+	time.Sleep(50 * time.Millisecond)
+	ss.book("slot-1")
+	if ch := nextChange(t, changes); ch.Type != client.SlotBooked || ch.SlotAfter.ID != "slot-1" {
+		t.Fatalf("change = %s %+v, want slot-1 booked", ch.Type, ch.SlotAfter)
+	}
+
+	ss.mu.Lock()
+	ss.failGet = true
+	ss.mu.Unlock()
+	if ch := nextChange(t, changes); ch.Type != client.WatchFailed || ch.Err == nil {
+		t.Fatalf("change = %s (%v), want WatchFailed with the error", ch.Type, ch.Err)
+	}
+
+	cancel()
+	for range changes {
+	}
+}
+
+func TestMockClient_WatchUpcoming(t *testing.T) {
+	now := time.Now()
+	ss := newSlotServer(map[string]*mockSlot{})
+	c := newSlotClient(t, ss)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	changes := c.WatchUpcoming(ctx, 24*time.Hour, 20*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	// Only the slot inside the window is reported, not the one that already
+	// ended or the one a week ahead
+	ss.mu.Lock()
+	ss.slots["past"] = &mockSlot{start: now.Add(-2 * time.Hour), end: now.Add(-time.Hour)}
+	ss.slots["later"] = &mockSlot{start: now.Add(7 * 24 * time.Hour), end: now.Add(7*24*time.Hour + time.Hour)}
+	ss.slots["soon"] = &mockSlot{start: now.Add(2 * time.Hour), end: now.Add(3 * time.Hour)}
+	ss.mu.Unlock()
+
+	if ch := nextChange(t, changes); ch.Type != client.SlotAdded || ch.SlotAfter.ID != "soon" {
+		t.Fatalf("change = %s %+v, want only the slot within the window", ch.Type, ch.SlotAfter)
+	}
+}
+
+func TestMovingWindowChanges(t *testing.T) {
+	now := slotBase()
+	prevTo := now.Add(24 * time.Hour)
+	slot := func(id string, start time.Time) *client.ReviewSlot {
+		return &client.ReviewSlot{ID: id, Start: start, End: start.Add(time.Hour)}
+	}
+
+	changes := []client.CalendarChange{
+		{Type: client.SlotRemoved, SlotBefore: slot("ended", now.Add(-2*time.Hour))},
+		{Type: client.SlotRemoved, SlotBefore: slot("deleted", now.Add(time.Hour))},
+		{Type: client.SlotAdded, SlotAfter: slot("entered", prevTo)},
+		{Type: client.SlotAdded, SlotAfter: slot("created", prevTo.Add(-2*time.Hour))},
+		{Type: client.SlotBooked, SlotBefore: slot("booked", prevTo), SlotAfter: slot("booked", prevTo)},
+	}
+	kept := client.MovingWindowChanges(changes, now, prevTo)
+
+	var ids []string
+	for _, ch := range kept {
+		if ch.SlotAfter != nil {
+			ids = append(ids, ch.SlotAfter.ID)
+		} else {
+			ids = append(ids, ch.SlotBefore.ID)
+		}
+	}
+	want := []string{"deleted", "created", "booked"}
+	if len(ids) != len(want) {
+		t.Fatalf("MovingWindowChanges() kept %v, want %v", ids, want)
+	}
+	for i := range want {
+		if ids[i] != want[i] {
+			t.Errorf("kept[%d] = %s, want %s", i, ids[i], want[i])
+		}
+	}
+}